@@ -0,0 +1,125 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/convert"
+	"github.com/score-spec/score-helm/internal/state"
+)
+
+// ChartNamePlaceholder is replaced with the chart name in every file of the embedded chart.
+const ChartNamePlaceholder = "<CHARTNAME>"
+
+// Files holds the chart that matches the values layout produced by the convert package. It is a copy of
+// examples/chart without the Chart.yaml, which is generated from the Metadata instead, and without the
+// values.schema.json, which is taken from the convert package.
+//
+//go:embed all:files
+var Files embed.FS
+
+// Metadata is the content of the generated Chart.yaml.
+type Metadata struct {
+	ApiVersion  string `yaml:"apiVersion"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Type        string `yaml:"type"`
+	Version     string `yaml:"version"`
+	AppVersion  string `yaml:"appVersion,omitempty"`
+	// Dependencies are the charts returned by resource provisioners, such as subcharts that deploy a resource.
+	Dependencies []state.ChartDependency `yaml:"dependencies,omitempty"`
+}
+
+// NewMetadata returns the metadata of an application chart with the given name and versions.
+func NewMetadata(name string, version string, appVersion string) Metadata {
+	return Metadata{
+		ApiVersion:  "v2",
+		Name:        name,
+		Description: fmt.Sprintf("A Helm chart for the %s Score workload", name),
+		Type:        "application",
+		Version:     version,
+		AppVersion:  appVersion,
+	}
+}
+
+// Write writes the Chart.yaml and the embedded chart files to the given directory. Existing chart files are
+// overwritten, while any other files in the directory are left as is.
+func Write(dir string, metadata Metadata) error {
+	if metadata.Name == "" {
+		return fmt.Errorf("chart name is empty")
+	} else if metadata.Version == "" {
+		return fmt.Errorf("chart version is empty")
+	}
+	rawMetadata := new(bytes.Buffer)
+	enc := yaml.NewEncoder(rawMetadata)
+	enc.SetIndent(2)
+	if err := enc.Encode(metadata); err != nil {
+		return fmt.Errorf("failed to encode Chart.yaml: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create chart directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), rawMetadata.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write Chart.yaml: %w", err)
+	}
+
+	files, err := chartFiles(metadata.Name)
+	if err != nil {
+		return err
+	}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create chart directory: %w", err)
+		}
+		if err := os.WriteFile(target, files[path], 0644); err != nil {
+			return fmt.Errorf("failed to write chart file '%s': %w", path, err)
+		}
+	}
+	return nil
+}
+
+// chartFiles returns the content of the embedded chart files with the chart name filled in, keyed by their slash
+// separated path in the chart.
+func chartFiles(name string) (map[string][]byte, error) {
+	output := make(map[string][]byte)
+	root, _ := fs.Sub(Files, "files")
+	if err := fs.WalkDir(root, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		raw, err := fs.ReadFile(root, path)
+		if err != nil {
+			return err
+		}
+		output[path] = []byte(strings.ReplaceAll(string(raw), ChartNamePlaceholder, name))
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read embedded chart: %w", err)
+	}
+	output[convert.ValuesSchemaFileName] = []byte(convert.ValuesSchema)
+	return output, nil
+}
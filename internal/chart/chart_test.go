@@ -0,0 +1,291 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/convert"
+)
+
+// TestFilesMatchExampleChart ensures that the embedded chart is kept in sync with examples/chart.
+func TestFilesMatchExampleChart(t *testing.T) {
+	exampleDir := filepath.Join("..", "..", "examples", "chart")
+	root, _ := fs.Sub(Files, "files")
+
+	embedded := make(map[string]string)
+	require.NoError(t, fs.WalkDir(root, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			raw, _ := fs.ReadFile(root, path)
+			embedded[path] = string(raw)
+		}
+		return err
+	}))
+
+	example := make(map[string]string)
+	require.NoError(t, filepath.WalkDir(exampleDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(exampleDir, path)
+			if rel != "Chart.yaml" && rel != convert.ValuesSchemaFileName {
+				raw, _ := os.ReadFile(path)
+				example[filepath.ToSlash(rel)] = string(raw)
+			}
+		}
+		return err
+	}))
+
+	assert.Equal(t, example, embedded)
+
+	raw, err := os.ReadFile(filepath.Join(exampleDir, convert.ValuesSchemaFileName))
+	require.NoError(t, err)
+	assert.Equal(t, convert.ValuesSchema, string(raw))
+}
+
+func TestWrite(t *testing.T) {
+	td := t.TempDir()
+	require.NoError(t, Write(td, NewMetadata("example", "1.2.3", "4.5.6")))
+
+	raw, err := os.ReadFile(filepath.Join(td, "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v2
+name: example
+description: A Helm chart for the example Score workload
+type: application
+version: 1.2.3
+appVersion: 4.5.6
+`, string(raw))
+
+	for _, name := range []string{".helmignore", "values.yaml", "templates/_helpers.tpl", "templates/deployment.yaml", "templates/service.yaml", "templates/configmap.yaml", "templates/secret.yaml", "templates/pvc.yaml", "templates/ingress.yaml", "templates/httproute.yaml", "values.schema.json"} {
+		raw, err := os.ReadFile(filepath.Join(td, name))
+		if assert.NoError(t, err) {
+			assert.NotContains(t, string(raw), ChartNamePlaceholder)
+		}
+	}
+	raw, _ = os.ReadFile(filepath.Join(td, "templates", "_helpers.tpl"))
+	assert.Contains(t, string(raw), `define "example.workloads"`)
+}
+
+func TestWrite_missing_version(t *testing.T) {
+	assert.EqualError(t, Write(t.TempDir(), NewMetadata("example", "", "")), "chart version is empty")
+}
+
+func TestLoadAndRender(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "example", chrt.Name())
+
+	manifests, err := Render(context.Background(), chrt, map[string]interface{}{
+		"containers": map[string]interface{}{
+			"main": map[string]interface{}{
+				"image": map[string]interface{}{"name": "busybox"},
+				"files": []interface{}{
+					map[string]interface{}{"target": "/etc/config", "content": "hello"},
+				},
+			},
+		},
+		"service": map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"name": "web", "port": 80}},
+		},
+	}, "example", "default")
+	require.NoError(t, err)
+
+	kinds := make(map[string]string)
+	dec := yaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		var doc struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		kinds[doc.Kind] = doc.Metadata.Name
+	}
+	assert.Equal(t, map[string]string{
+		"ConfigMap":  "example-main-files",
+		"Deployment": "example",
+		"Service":    "example",
+	}, kinds)
+}
+
+func TestRender_invalid_chart_values(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	_, err = Render(context.Background(), chrt, map[string]interface{}{
+		"containers": "not-a-map",
+	}, "example", "default")
+	assert.ErrorContains(t, err, "values don't meet the specifications of the schema(s)")
+}
+
+func TestRender_annotations_and_labels(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	manifests, err := Render(context.Background(), chrt, map[string]interface{}{
+		"containers": map[string]interface{}{
+			"main": map[string]interface{}{"image": map[string]interface{}{"name": "busybox"}},
+		},
+		"annotations":    map[string]interface{}{"example.com/cost-centre": "payments"},
+		"podAnnotations": map[string]interface{}{"sidecar.istio.io/inject": "true"},
+		"labels":         map[string]interface{}{"team": "payments", "app.kubernetes.io/name": "other"},
+	}, "example", "default")
+	require.NoError(t, err)
+	assert.Contains(t, manifests, `kind: Deployment
+metadata:
+  name: example
+  labels:
+    app.kubernetes.io/component: example
+    app.kubernetes.io/instance: example
+    app.kubernetes.io/managed-by: Helm
+    app.kubernetes.io/name: example
+    helm.sh/chart: example-0.1.0
+    team: payments
+  annotations:
+    example.com/cost-centre: payments
+`)
+	assert.Contains(t, manifests, `  template:
+    metadata:
+      labels:
+        app.kubernetes.io/component: example
+        app.kubernetes.io/instance: example
+        app.kubernetes.io/name: example
+        team: payments
+      annotations:
+        sidecar.istio.io/inject: "true"
+`)
+}
+
+func TestRender_extensions(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	manifests, err := Render(context.Background(), chrt, map[string]interface{}{
+		"containers": map[string]interface{}{
+			"main": map[string]interface{}{
+				"image":           map[string]interface{}{"name": "busybox"},
+				"securityContext": map[string]interface{}{"readOnlyRootFilesystem": true},
+			},
+		},
+		"replicas":           0,
+		"serviceAccountName": "example",
+		"podSecurityContext": map[string]interface{}{"runAsNonRoot": true},
+		"service": map[string]interface{}{
+			"type":  "NodePort",
+			"ports": []interface{}{map[string]interface{}{"name": "web", "port": 80}},
+		},
+	}, "example", "default")
+	require.NoError(t, err)
+	assert.Contains(t, manifests, "spec:\n  replicas: 0\n")
+	assert.Contains(t, manifests, `    spec:
+      serviceAccountName: example
+      securityContext:
+        runAsNonRoot: true
+`)
+	assert.Contains(t, manifests, `          securityContext:
+            readOnlyRootFilesystem: true
+`)
+	assert.Contains(t, manifests, "spec:\n  type: NodePort\n")
+}
+
+func TestRender_headless_service(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	manifests, err := Render(context.Background(), chrt, map[string]interface{}{
+		"containers": map[string]interface{}{
+			"main": map[string]interface{}{"image": map[string]interface{}{"name": "busybox"}},
+		},
+		"service": map[string]interface{}{
+			"type":      "ClusterIP",
+			"clusterIP": "None",
+			"ports": []interface{}{
+				map[string]interface{}{"name": "web", "port": 80, "appProtocol": "http"},
+			},
+		},
+	}, "example", "default")
+	require.NoError(t, err)
+	assert.Contains(t, manifests, "spec:\n  type: ClusterIP\n  clusterIP: None\n")
+	assert.Contains(t, manifests, "  ports:\n    - appProtocol: http\n      name: web\n      port: 80\n")
+}
+
+func TestRender_routes(t *testing.T) {
+	chrt, err := Load(NewMetadata("example", "0.1.0", ""))
+	require.NoError(t, err)
+	values := map[string]interface{}{
+		"workloads": map[string]interface{}{
+			"web": map[string]interface{}{
+				"containers": map[string]interface{}{
+					"main": map[string]interface{}{"image": map[string]interface{}{"name": "busybox"}},
+				},
+			},
+		},
+		"routes": map[string]interface{}{
+			"route-1": map[string]interface{}{"workload": "web", "host": "example.com", "path": "/api", "port": 8080},
+		},
+		"ingress": map[string]interface{}{"className": "nginx"},
+	}
+	manifests, err := Render(context.Background(), chrt, values, "example", "default")
+	require.NoError(t, err)
+	assert.Contains(t, manifests, `spec:
+  ingressClassName: nginx
+  rules:
+    - host: "example.com"
+      http:
+        paths:
+          - path: "/api"
+            pathType: Prefix
+            backend:
+              service:
+                name: web
+                port:
+                  number: 8080
+`)
+	assert.NotContains(t, manifests, "kind: HTTPRoute")
+
+	values["ingress"] = map[string]interface{}{"enabled": false}
+	values["httpRoute"] = map[string]interface{}{
+		"enabled":    true,
+		"parentRefs": []interface{}{map[string]interface{}{"name": "gateway"}},
+	}
+	manifests, err = Render(context.Background(), chrt, values, "example", "default")
+	require.NoError(t, err)
+	assert.NotContains(t, manifests, "kind: Ingress")
+	assert.Contains(t, manifests, `spec:
+  parentRefs:
+    - name: gateway
+  hostnames:
+    - "example.com"
+  rules:
+    - matches:
+        - path:
+            type: PathPrefix
+            value: "/api"
+      backendRefs:
+        - name: web
+          port: 8080
+`)
+}
@@ -0,0 +1,95 @@
+{{/*
+Expand the name of the chart.
+*/}}
+{{- define "<CHARTNAME>.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{/*
+Create a default fully qualified app name.
+We truncate at 63 chars because some Kubernetes name fields are limited to this (by the DNS naming spec).
+If release name contains chart name it will be used as a full name.
+*/}}
+{{- define "<CHARTNAME>.fullname" -}}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{/*
+Create chart name and version as used by the chart label.
+*/}}
+{{- define "<CHARTNAME>.chart" -}}
+{{- printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{/*
+Common labels
+*/}}
+{{- define "<CHARTNAME>.labels" -}}
+helm.sh/chart: {{ include "<CHARTNAME>.chart" . }}
+{{ include "<CHARTNAME>.selectorLabels" . }}
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+{{/*
+Selector labels
+*/}}
+{{- define "<CHARTNAME>.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "<CHARTNAME>.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+{{/*
+Labels of the resources of a workload: the common labels, the workload component, and the custom labels of the
+workload. The common labels take precedence over custom labels of the same name.
+Usage: include "<CHARTNAME>.workloadLabels" (dict "root" $ "name" $workloadName "workload" $workload)
+*/}}
+{{- define "<CHARTNAME>.workloadLabels" -}}
+{{- $labels := include "<CHARTNAME>.labels" .root | fromYaml }}
+{{- $_ := set $labels "app.kubernetes.io/component" .name }}
+{{- toYaml (merge $labels (default dict .workload.labels)) }}
+{{- end }}
+{{/*
+Labels of the pods of a workload: the selector labels, the workload component, and the custom labels of the workload.
+The selector labels take precedence over custom labels of the same name.
+Usage: include "<CHARTNAME>.podLabels" (dict "root" $ "name" $workloadName "workload" $workload)
+*/}}
+{{- define "<CHARTNAME>.podLabels" -}}
+{{- $labels := include "<CHARTNAME>.selectorLabels" .root | fromYaml }}
+{{- $_ := set $labels "app.kubernetes.io/component" .name }}
+{{- toYaml (merge $labels (default dict .workload.labels)) }}
+{{- end }}
+{{/*
+Workloads keyed by the name of their Kubernetes resources. A values file generated from a single Score file holds
+the workload at the top level and it is named after the release. A values file generated from multiple Score files
+holds each workload under workloads.<name> and they are named after the workload.
+*/}}
+{{- define "<CHARTNAME>.workloads" -}}
+{{- if .Values.workloads }}
+{{- toYaml .Values.workloads }}
+{{- else }}
+{{ include "<CHARTNAME>.fullname" . }}:
+  {{- toYaml .Values | nindent 2 }}
+{{- end }}
+{{- end }}
+{{/*
+The key of a Score workload in "<CHARTNAME>.workloads".
+Usage: include "<CHARTNAME>.workloadKey" (dict "root" $ "workload" $scoreWorkloadName)
+*/}}
+{{- define "<CHARTNAME>.workloadKey" -}}
+{{- if .root.Values.workloads }}
+{{- .workload }}
+{{- else }}
+{{- include "<CHARTNAME>.fullname" .root }}
+{{- end }}
+{{- end }}
+{{/*
+The name of the Service of a workload. This is the name of the Score workload in service.name, so that it matches the
+service_name given to provisioners in both layouts, and falls back to the key of the workload.
+Usage: include "<CHARTNAME>.serviceName" (dict "key" $workloadKey "workload" $workload)
+*/}}
+{{- define "<CHARTNAME>.serviceName" -}}
+{{- default .key (dig "name" "" (default dict .workload.service)) }}
+{{- end }}
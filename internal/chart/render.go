@@ -0,0 +1,88 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	helmloader "helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// Load loads the embedded chart in memory with the given metadata, as if it had been written by Write.
+func Load(metadata Metadata) (*helmchart.Chart, error) {
+	rawMetadata, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Chart.yaml: %w", err)
+	}
+	files := []*helmloader.BufferedFile{{Name: "Chart.yaml", Data: rawMetadata}}
+
+	embedded, err := chartFiles(metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range slices.Sorted(maps.Keys(embedded)) {
+		files = append(files, &helmloader.BufferedFile{Name: path, Data: embedded[path]})
+	}
+	return helmloader.LoadFiles(files)
+}
+
+// LoadDirectory loads a chart from a directory or packaged chart archive.
+func LoadDirectory(path string) (*helmchart.Chart, error) {
+	return helmloader.Load(path)
+}
+
+// Render renders the chart with the given values in the same way as 'helm template', without access to a Kubernetes
+// cluster. The manifests are returned as a single multi-document YAML string including any hook manifests. Like
+// 'helm template', this fails when the chart dependencies have not been fetched into the charts directory.
+func Render(ctx context.Context, chrt *helmchart.Chart, values map[string]interface{}, releaseName string, namespace string) (string, error) {
+	if dependencies := chrt.Metadata.Dependencies; len(dependencies) > 0 {
+		if err := action.CheckDependencies(chrt, dependencies); err != nil {
+			return "", fmt.Errorf("run 'helm dependency build' on the chart first: %w", err)
+		}
+	}
+
+	cfg := &action.Configuration{
+		Log: func(format string, v ...interface{}) {
+			slog.Debug(fmt.Sprintf(format, v...))
+		},
+	}
+	client := action.NewInstall(cfg)
+	client.DryRun = true
+	client.DryRunOption = "client"
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
+	client.ReleaseName = releaseName
+	client.Namespace = namespace
+
+	rel, err := client.RunWithContext(ctx, chrt, values)
+	if err != nil {
+		return "", err
+	}
+	out := new(strings.Builder)
+	out.WriteString(strings.TrimSpace(rel.Manifest) + "\n")
+	for _, hook := range rel.Hooks {
+		_, _ = fmt.Fprintf(out, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+	return out.String(), nil
+}
@@ -16,9 +16,12 @@ package command
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -30,8 +33,11 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/score-spec/score-helm/internal/chart"
 	"github.com/score-spec/score-helm/internal/convert"
+	"github.com/score-spec/score-helm/internal/extensions"
 	"github.com/score-spec/score-helm/internal/provisioners"
+	"github.com/score-spec/score-helm/internal/provisioners/loader"
 	"github.com/score-spec/score-helm/internal/state"
 )
 
@@ -40,6 +46,13 @@ const (
 	generateCmdOverridePropertyFlag = "override-property"
 	generateCmdImageFlag            = "image"
 	generateCmdOutputFlag           = "output"
+	generateCmdChartFlag            = "chart"
+	generateCmdChartNameFlag        = "chart-name"
+	generateCmdChartVersionFlag     = "chart-version"
+	generateCmdAppVersionFlag       = "app-version"
+	generateCmdValuesSchemaFlag     = "values-schema"
+	generateCmdExtensionsFileFlag   = "extensions-file"
+	generateCmdValuesTemplateFlag   = "values-template"
 )
 
 var generateCmd = &cobra.Command{
@@ -53,18 +66,41 @@ var generateCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
-		sd, ok, err := state.LoadStateDirectory(".")
+		sd, lock, ok, err := loadAndLockStateDirectory(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to load existing state directory: %w", err)
 		} else if !ok {
-			return fmt.Errorf("state directory does not exist, please run \"init\" first")
+			return stateDirectoryNotFoundError(cmd)
 		}
+		defer releaseLock(lock)
 		currentState := &sd.State
 
 		if len(args) != 1 && (cmd.Flags().Lookup(generateCmdOverridesFileFlag).Changed || cmd.Flags().Lookup(generateCmdOverridePropertyFlag).Changed || cmd.Flags().Lookup(generateCmdImageFlag).Changed) {
 			return fmt.Errorf("cannot use --%s, --%s, or --%s when 0 or more than 1 score files are provided", generateCmdOverridePropertyFlag, generateCmdOverridesFileFlag, generateCmdImageFlag)
 		}
 
+		// A values template in the state directory replaces the default values template unless one is given explicitly
+		var valuesTemplate string
+		if v, _ := cmd.Flags().GetString(generateCmdValuesTemplateFlag); v != "" {
+			raw, err := os.ReadFile(v)
+			if err != nil {
+				return fmt.Errorf("--%s '%s' is invalid, failed to read file: %w", generateCmdValuesTemplateFlag, v, err)
+			}
+			valuesTemplate = string(raw)
+			slog.Info(fmt.Sprintf("Using values template from %s", v))
+		} else {
+			for _, configPath := range sd.ConfigPaths() {
+				path := filepath.Join(configPath, state.ValuesTemplateFileName)
+				if raw, err := os.ReadFile(path); err == nil {
+					valuesTemplate = string(raw)
+					slog.Info(fmt.Sprintf("Using values template from %s", path))
+					break
+				} else if !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to read values template: %w", err)
+				}
+			}
+		}
+
 		slices.Sort(args)
 		for _, arg := range args {
 			var rawWorkload map[string]interface{}
@@ -120,7 +156,9 @@ var generateCmd = &cobra.Command{
 				}
 			}
 
-			if currentState, err = currentState.WithWorkload(&workload, &arg, state.WorkloadExtras{}); err != nil {
+			// Keep the extras of a workload that was generated before, such as its extensions
+			extras := currentState.Workloads[workload.Metadata["name"].(string)].Extras
+			if currentState, err = currentState.WithWorkload(&workload, &arg, extras); err != nil {
 				return fmt.Errorf("failed to add score file to project: %s: %w", arg, err)
 			}
 			slog.Info("Added score file to project", "file", arg)
@@ -130,13 +168,47 @@ var generateCmd = &cobra.Command{
 			return fmt.Errorf("project is empty, please add a score file")
 		}
 
-		if currentState, err = currentState.WithPrimedResources(); err != nil {
+		if v, _ := cmd.Flags().GetString(generateCmdExtensionsFileFlag); v != "" {
+			workloadExtensions, err := extensions.LoadFile(v)
+			if err != nil {
+				return fmt.Errorf("--%s '%s' is invalid: %w", generateCmdExtensionsFileFlag, v, err)
+			}
+			for workloadName, workloadExtension := range workloadExtensions {
+				workloadState, ok := currentState.Workloads[workloadName]
+				if !ok {
+					return fmt.Errorf("--%s '%s' is invalid: no workload named '%s'", generateCmdExtensionsFileFlag, v, workloadName)
+				}
+				workloadState.Extras.Extensions = &workloadExtension
+				currentState.Workloads[workloadName] = workloadState
+				slog.Info(fmt.Sprintf("Applied extensions from %s to workload '%s'", v, workloadName))
+			}
+			// The file replaces the extensions kept in the state, so workloads that it leaves out lose theirs
+			for workloadName, workloadState := range currentState.Workloads {
+				if _, ok := workloadExtensions[workloadName]; !ok && workloadState.Extras.Extensions != nil {
+					workloadState.Extras.Extensions = nil
+					currentState.Workloads[workloadName] = workloadState
+					slog.Info(fmt.Sprintf("Cleared extensions of workload '%s' since %s does not name it", workloadName, v))
+				}
+			}
+		}
+
+		if currentState, err = state.WithPrimedResources(currentState); err != nil {
 			return fmt.Errorf("failed to prime resources: %w", err)
 		}
 
 		slog.Info("Primed resources", "#workloads", len(currentState.Workloads), "#resources", len(currentState.Resources))
 
-		if currentState, err = provisioners.ProvisionResources(currentState); err != nil {
+		// The provisioners of an environment are loaded first so that they take precedence over the shared ones
+		var loadedProvisioners []provisioners.Provisioner
+		for _, configPath := range sd.ConfigPaths() {
+			p, err := loader.LoadProvisionersFromDirectory(configPath, loader.DefaultSuffix)
+			if err != nil {
+				return fmt.Errorf("failed to load provisioners: %w", err)
+			}
+			loadedProvisioners = append(loadedProvisioners, p...)
+		}
+
+		if currentState, err = provisioners.ProvisionResources(cmd.Context(), currentState, loadedProvisioners); err != nil {
 			return fmt.Errorf("failed to provision resources: %w", err)
 		}
 
@@ -146,14 +218,41 @@ var generateCmd = &cobra.Command{
 		}
 		slog.Info("Persisted state file")
 
-		out := new(bytes.Buffer)
-		for workloadName := range currentState.Workloads {
-			if manifest, err := convert.Workload(currentState, workloadName); err != nil {
-				return fmt.Errorf("failed to convert workloads: %w", err)
-			} else {
-				out.WriteString(manifest)
+		workloadNames := slices.Sorted(maps.Keys(currentState.Workloads))
+		values, err := convert.WorkloadsWithTemplate(currentState, workloadNames, valuesTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to convert workloads: %w", err)
+		}
+		out := bytes.NewBufferString(values)
+		slog.Info(fmt.Sprintf("Wrote values to values buffer for workloads %v", workloadNames))
+
+		if v, _ := cmd.Flags().GetString(generateCmdChartFlag); v != "" {
+			chartName, _ := cmd.Flags().GetString(generateCmdChartNameFlag)
+			if chartName == "" && len(workloadNames) == 1 {
+				chartName = workloadNames[0]
+			} else if chartName == "" {
+				chartName = filepath.Base(filepath.Clean(v))
+			}
+			chartVersion, _ := cmd.Flags().GetString(generateCmdChartVersionFlag)
+			appVersion, _ := cmd.Flags().GetString(generateCmdAppVersionFlag)
+			metadata := chart.NewMetadata(chartName, chartVersion, appVersion)
+			if metadata.Dependencies, err = convert.Dependencies(currentState, workloadNames); err != nil {
+				return fmt.Errorf("failed to collect chart dependencies: %w", err)
+			}
+			if err := chart.Write(v, metadata); err != nil {
+				return fmt.Errorf("failed to write chart: %w", err)
+			}
+			slog.Info(fmt.Sprintf("Wrote chart '%s' to '%s'", chartName, v))
+			if len(metadata.Dependencies) > 0 {
+				slog.Info(fmt.Sprintf("The chart has %d dependencies, run 'helm dependency build %s' before installing it", len(metadata.Dependencies), v))
+			}
+		}
+
+		if v, _ := cmd.Flags().GetString(generateCmdValuesSchemaFlag); v != "" {
+			if err := os.WriteFile(v, []byte(convert.ValuesSchema), 0644); err != nil {
+				return fmt.Errorf("failed to write values schema: %w", err)
 			}
-			slog.Info(fmt.Sprintf("Wrote manifest to manifests buffer for workload '%s'", workloadName))
+			slog.Info(fmt.Sprintf("Wrote values schema to '%s'", v))
 		}
 
 		v, _ := cmd.Flags().GetString(generateCmdOutputFlag)
@@ -217,6 +316,13 @@ func init() {
 	generateCmd.Flags().StringP(generateCmdOutputFlag, "o", "values.yaml", "The output values file to write the workloads to")
 	generateCmd.Flags().String(generateCmdOverridesFileFlag, "", "An optional file of Score overrides to merge in")
 	generateCmd.Flags().StringArray(generateCmdOverridePropertyFlag, []string{}, "An optional set of path=key overrides to set or remove")
+	generateCmd.Flags().String(generateCmdChartFlag, "", "An optional directory to write a Helm chart matching the values output to")
+	generateCmd.Flags().String(generateCmdChartNameFlag, "", "The name of the chart written by --chart, defaults to the workload name or the chart directory name")
+	generateCmd.Flags().String(generateCmdChartVersionFlag, "0.1.0", "The version of the chart written by --chart")
+	generateCmd.Flags().String(generateCmdAppVersionFlag, "", "The optional app version of the chart written by --chart")
+	generateCmd.Flags().String(generateCmdExtensionsFileFlag, "", "An optional file of Kubernetes-specific workload settings, such as replicas, keyed by workload name")
+	generateCmd.Flags().String(generateCmdValuesTemplateFlag, "", "An optional Go template file to render the values of each workload with instead of the default, takes precedence over .score-helm/values.tmpl")
+	generateCmd.Flags().String(generateCmdValuesSchemaFlag, "", "An optional file to write the JSON schema of the values output to, such as a chart's values.schema.json")
 	generateCmd.Flags().StringP(generateCmdImageFlag, "i", "", "An optional container image to use for any container with image == '.'")
 	rootCmd.AddCommand(generateCmd)
 }
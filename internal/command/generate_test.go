@@ -16,13 +16,18 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/score-spec/score-helm/internal/convert"
 	"github.com/score-spec/score-helm/internal/state"
 )
 
@@ -106,6 +111,21 @@ func TestInitAndGenerate_with_sample(t *testing.T) {
 	assert.Len(t, sd.State.Workloads, 1)
 	// new sample declares 3 resources: postgres, dns, and route
 	assert.Len(t, sd.State.Resources, 3)
+
+	// the route is added to the values for the chart to render
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	var values struct {
+		Routes map[string]map[string]interface{} `yaml:"routes"`
+	}
+	require.NoError(t, yaml.Unmarshal(raw, &values))
+	require.Len(t, values.Routes, 1)
+	for _, route := range values.Routes {
+		assert.Equal(t, "hello-world", route["workload"])
+		assert.Equal(t, "/", route["path"])
+		assert.Equal(t, 8080, route["port"])
+		assert.Equal(t, sd.State.Resources["dns.default#hello-world.dns"].Outputs["host"], route["host"])
+	}
 }
 
 func TestInitAndGenerate_with_full_example(t *testing.T) {
@@ -145,11 +165,798 @@ resources:
 	assert.Equal(t, `containers:
   main:
     env:
-      - name: dynamic
+      - name: "dynamic"
         value: "example"
-      - name: key
+      - name: "key"
         value: "value"
+    files:
+      - target: "/somefile"
+        content: "example\n"
     image:
-      name: stefanprodan/podinfo
+      name: "stefanprodan/podinfo"
 `, string(raw))
 }
+
+func TestInitAndGenerate_with_files(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "config.txt"), []byte("a: <b> & 'c'\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: example
+containers:
+    main:
+        image: busybox
+        files:
+            /etc/config.txt:
+                source: config.txt
+                mode: "0600"
+            /etc/bin.dat:
+                binaryContent: aGVsbG8+Pz8/
+                mode: "644"
+            /etc/secret.txt:
+                content: |
+                    password=${resources.env.PASSWORD}
+resources:
+    env:
+        type: environment
+`), 0644))
+	t.Setenv("PASSWORD", "p+ss")
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "score.yaml",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `containers:
+  main:
+    files:
+      - target: "/etc/bin.dat"
+        mode: 420
+        binaryContent: "aGVsbG8+Pz8/"
+      - target: "/etc/config.txt"
+        mode: 384
+        content: "a: <b> & 'c'\n"
+      - target: "/etc/secret.txt"
+        content: "password=p+ss\n"
+        secret: true
+    image:
+      name: "busybox"
+`, stdout)
+}
+
+func TestInitAndGenerate_with_template_provisioner(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://custom-postgres
+  type: postgres
+  state: |
+    password: {{ dig "password" "s3cret" .State | quote }}
+  outputs: |
+    host: {{ .SourceWorkload }}-db
+    port: 5432
+    password: {{ .State.password }}
+  values: |
+    postgres:
+      enabled: true
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: example
+containers:
+    main:
+        image: busybox
+        variables:
+            DB: postgres://${resources.db.host}:${resources.db.port}
+resources:
+    db:
+        type: postgres
+`), 0644))
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "score.yaml",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `containers:
+  main:
+    env:
+      - name: DB
+        value: postgres://example-db:5432
+    image:
+      name: busybox
+postgres:
+  enabled: true
+`, stdout)
+
+	sd, ok, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	res := sd.State.Resources["postgres.default#example.db"]
+	assert.Equal(t, "template://custom-postgres", res.ProvisionerUri)
+	assert.Equal(t, map[string]interface{}{"password": "s3cret"}, res.State)
+}
+
+func TestInitAndGenerate_with_default_provisioners(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: frontend
+containers:
+    main:
+        image: busybox
+        variables:
+            GREETING: ${resources.env.GREETING}
+            BACKEND: http://${resources.backend.hostname}:${resources.backend.port}
+resources:
+    env:
+        type: environment
+    backend:
+        type: service-port
+        params:
+            workload: backend
+            port: web
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score2.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: backend
+containers:
+    main:
+        image: busybox
+service:
+    ports:
+        web:
+            port: 8080
+`), 0644))
+	t.Setenv("GREETING", "hello")
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "values.yaml", "--", "score.yaml", "score2.yaml",
+	})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `value: "http://backend:8080"`)
+	assert.Contains(t, string(raw), `value: "hello"`)
+}
+
+func TestInitAndGenerate_with_volumes(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://custom-volume
+  type: volume
+  outputs: |
+    source:
+      persistentVolumeClaim:
+        claimName: {{ .Id | replace "." "-" }}
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: example
+containers:
+    main:
+        image: busybox
+        volumes:
+            /mnt/data:
+                source: ${resources.data}
+                path: sub/path
+                readOnly: true
+    sidecar:
+        image: busybox
+        volumes:
+            /data:
+                source: ${resources.data}
+resources:
+    data:
+        type: volume
+`), 0644))
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "score.yaml",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `containers:
+  main:
+    image:
+      name: "busybox"
+    volumeMounts:
+      - name: data
+        mountPath: "/mnt/data"
+        subPath: "sub/path"
+        readOnly: true
+  sidecar:
+    image:
+      name: "busybox"
+    volumeMounts:
+      - name: data
+        mountPath: "/data"
+volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: example-data
+`, stdout)
+}
+
+func TestInitAndGenerate_with_invalid_volume_source(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: example
+containers:
+    main:
+        image: busybox
+        volumes:
+            /mnt/data:
+                source: ${resources.data}
+resources:
+    data:
+        type: dns
+`), 0644))
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "score.yaml",
+	})
+	assert.EqualError(t, err, "failed to convert workloads: workload: example: container: main: volumes: /mnt/data: source: resource 'data' has no volume source output: key 'source' not found")
+}
+
+func TestInitAndGenerate_with_multiple_workloads(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://custom-thing
+  type: thing
+  outputs: |
+    name: {{ .Id }}
+  values: |
+    things:
+      {{ .Id }}: true
+`), 0644))
+	for _, name := range []string{"zeta", "alpha", "mid"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(td, name+".yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: `+name+`
+containers:
+    main:
+        image: busybox
+        variables:
+            THING: ${resources.thing.name}
+service:
+    ports:
+        web:
+            port: 80
+resources:
+    thing:
+        type: thing
+        id: shared
+`), 0644))
+	}
+
+	for i := 0; i < 2; i++ {
+		stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{
+			"generate", "-o", "-", "--", "zeta.yaml", "alpha.yaml", "mid.yaml",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `things:
+  shared: true
+workloads:
+  alpha:
+    containers:
+      main:
+        env:
+          - name: THING
+            value: shared
+        image:
+          name: busybox
+    service:
+      name: alpha
+      ports:
+        - name: web
+          port: 80
+  mid:
+    containers:
+      main:
+        env:
+          - name: THING
+            value: shared
+        image:
+          name: busybox
+    service:
+      name: mid
+      ports:
+        - name: web
+          port: 80
+  zeta:
+    containers:
+      main:
+        env:
+          - name: THING
+            value: shared
+        image:
+          name: busybox
+    service:
+      name: zeta
+      ports:
+        - name: web
+          port: 80
+`, stdout)
+	}
+}
+
+func TestInitAndGenerate_with_shared_resource(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://custom-thing
+  type: thing
+  outputs: |
+    size: {{ .Params.size }}
+    consumers: {{ join "," .Consumers }}
+`), 0644))
+	for name, params := range map[string]string{"first": "\n        params:\n            size: 2", "second": ""} {
+		assert.NoError(t, os.WriteFile(filepath.Join(td, name+".yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: `+name+`
+containers:
+    main:
+        image: busybox
+        variables:
+            THING: ${resources.thing.size}/${resources.thing.consumers}
+resources:
+    thing:
+        type: thing
+        id: shared`+params+`
+`), 0644))
+	}
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "first.yaml", "second.yaml",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(stdout, `value: "2/first,second"`))
+
+	sd, ok, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"first", "second"}, sd.State.Resources["thing.default#shared"].Extras.Consumers)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(td, "second.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+    name: second
+containers:
+    main:
+        image: busybox
+resources:
+    thing:
+        type: thing
+        id: shared
+        params:
+            size: 3
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "-o", "-", "--", "second.yaml",
+	})
+	assert.EqualError(t, err, "failed to prime resources: resource 'thing.default#shared': workloads first, second share this resource but declare different params")
+}
+
+func TestInitAndGenerate_with_chart(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "score.yaml", "--chart", "chart", "--chart-version", "1.0.0", "--app-version", "2.0.0",
+	})
+	require.NoError(t, err)
+
+	raw, err := os.ReadFile(filepath.Join(td, "chart", "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v2
+name: hello-world
+description: A Helm chart for the hello-world Score workload
+type: application
+version: 1.0.0
+appVersion: 2.0.0
+`, string(raw))
+	for _, name := range []string{"values.yaml", "templates/deployment.yaml", "templates/service.yaml", "templates/configmap.yaml", "templates/secret.yaml", "templates/pvc.yaml"} {
+		_, err = os.Stat(filepath.Join(td, "chart", name))
+		assert.NoError(t, err, name)
+	}
+	raw, err = os.ReadFile(filepath.Join(td, "chart", "templates", "deployment.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `include "hello-world.workloads"`)
+
+	// the values output is still written
+	_, err = os.Stat(filepath.Join(td, "values.yaml"))
+	assert.NoError(t, err)
+
+	raw, err = os.ReadFile(filepath.Join(td, "chart", "values.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, convert.ValuesSchema, string(raw))
+}
+
+func TestInitAndGenerate_with_values_schema(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "score.yaml", "--values-schema", "my-chart.schema.json",
+	})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(td, "my-chart.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, convert.ValuesSchema, string(raw))
+}
+
+func TestInitAndGenerate_with_extensions_file(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, "extensions.yaml"), []byte(`hello-world:
+  replicas: 3
+  service:
+    type: LoadBalancer
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "score.yaml", "--extensions-file", "extensions.yaml",
+	})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "replicas: 3\n")
+	assert.Contains(t, string(raw), "  type: LoadBalancer\n")
+
+	// the extensions are kept in the state when generating again without the file
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+	raw, err = os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "replicas: 3\n")
+}
+
+func TestInitAndGenerate_with_extensions_file_replaced(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+	for _, name := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(td, name+".yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: `+name+`
+containers:
+  main:
+    image: busybox
+`), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(td, "extensions.yaml"), []byte("first:\n  replicas: 2\nsecond:\n  replicas: 3\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "first.yaml", "second.yaml", "--extensions-file", "extensions.yaml",
+	})
+	require.NoError(t, err)
+
+	// a new file that leaves out a workload clears the extensions of that workload
+	require.NoError(t, os.WriteFile(filepath.Join(td, "extensions.yaml"), []byte("first:\n  replicas: 4\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "--extensions-file", "extensions.yaml",
+	})
+	require.NoError(t, err)
+
+	sd, ok, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	if assert.NotNil(t, sd.State.Workloads["first"].Extras.Extensions) {
+		assert.Equal(t, 4, *sd.State.Workloads["first"].Extras.Extensions.Replicas)
+	}
+	assert.Nil(t, sd.State.Workloads["second"].Extras.Extensions)
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "replicas: 4\n")
+	assert.NotContains(t, string(raw), "replicas: 3\n")
+}
+
+func TestInitAndGenerate_with_extensions_file_unknown_workload(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, "extensions.yaml"), []byte("other:\n  replicas: 3\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"generate", "score.yaml", "--extensions-file", "extensions.yaml",
+	})
+	assert.EqualError(t, err, "--extensions-file 'extensions.yaml' is invalid: no workload named 'other'")
+}
+
+func TestInitAndGenerate_with_values_template(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-default-provisioners"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+`), 0644))
+
+	// the values template in the state directory is picked up
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "values.tmpl"), []byte("app: {{ quoteYaml .WorkloadName }}\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "app: \"example\"\n", string(raw))
+
+	// and the flag takes precedence
+	require.NoError(t, os.WriteFile(filepath.Join(td, "custom.tmpl"), []byte("name: {{ quoteYaml .WorkloadName }}\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--values-template", "custom.tmpl"})
+	require.NoError(t, err)
+	raw, err = os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: \"example\"\n", string(raw))
+}
+
+func TestInitAndGenerate_with_missing_values_template(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--values-template", "missing.tmpl"})
+	assert.EqualError(t, err, "--values-template 'missing.tmpl' is invalid, failed to read file: open missing.tmpl: no such file or directory")
+}
+
+func TestInitAndGenerate_with_chart_dependencies(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-default-provisioners"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://subchart-postgres
+  type: postgres
+  init: |
+    name: pg-{{ .SourceWorkload }}
+  outputs: |
+    host: {{ .Init.name }}
+    port: 5432
+  values: |
+    postgresql:
+      fullnameOverride: {{ .Init.name }}
+  dependencies: |
+    - name: postgresql
+      version: 16.x.x
+      repository: oci://registry-1.docker.io/bitnamicharts
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+    variables:
+      DB_HOST: ${resources.db.host}
+resources:
+  db:
+    type: postgres
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--chart", "chart"})
+	require.NoError(t, err)
+
+	raw, err := os.ReadFile(filepath.Join(td, "chart", "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v2
+name: example
+description: A Helm chart for the example Score workload
+type: application
+version: 0.1.0
+dependencies:
+  - name: postgresql
+    version: 16.x.x
+    repository: oci://registry-1.docker.io/bitnamicharts
+`, string(raw))
+
+	raw, err = os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "postgresql:\n  fullnameOverride: pg-example\n")
+	assert.Contains(t, string(raw), "- name: DB_HOST\n        value: pg-example\n")
+}
+
+func TestInitAndGenerate_with_removed_provisioner(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-default-provisioners"})
+	require.NoError(t, err)
+	provisionersPath := filepath.Join(td, ".score-helm", "00-custom.provisioners.yaml")
+	require.NoError(t, os.WriteFile(provisionersPath, []byte(`
+- uri: template://subchart-postgres
+  type: postgres
+  outputs: |
+    host: pg
+  dependencies: |
+    - name: postgresql
+      version: 16.x.x
+      repository: oci://registry-1.docker.io/bitnamicharts
+  secrets: |
+    password:
+      name: pg
+      key: password
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+resources:
+  db:
+    type: postgres
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+	sd, _, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sd.State.Resources["postgres.default#example.db"].Extras.Dependencies)
+	assert.NotEmpty(t, sd.State.Resources["postgres.default#example.db"].Extras.Secrets)
+
+	// without a matching provisioner, nothing from the previous provisioner is left behind
+	require.NoError(t, os.Remove(provisionersPath))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--chart", "chart"})
+	require.NoError(t, err)
+	sd, _, err = state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	res := sd.State.Resources["postgres.default#example.db"]
+	assert.Empty(t, res.ProvisionerUri)
+	assert.Empty(t, res.Outputs)
+	assert.Nil(t, res.Extras.Dependencies)
+	assert.Nil(t, res.Extras.Secrets)
+	raw, err := os.ReadFile(filepath.Join(td, "chart", "Chart.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "dependencies")
+}
+
+func TestInitAndGenerate_with_secret_outputs(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+    variables:
+      DB_HOST: ${resources.db.host}
+      DB_PASSWORD: ${resources.db.password}
+      DB_URL: postgres://${resources.db.username}:${resources.db.password}@${resources.db.host}:${resources.db.port}/${resources.db.name}
+resources:
+  db:
+    type: postgres
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+
+	sd, _, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	res := sd.State.Resources["postgres.default#example.db"]
+	name := res.Outputs["host"].(string)
+	assert.Equal(t, map[string]state.SecretKeyRef{"password": {Name: name, Key: "password"}}, res.Extras.Secrets)
+	assert.NotContains(t, res.Outputs, "password")
+	assert.NotContains(t, res.State, "password")
+
+	raw, err := os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	var values struct {
+		Containers map[string]struct {
+			Env []map[string]interface{} `yaml:"env"`
+		} `yaml:"containers"`
+		GeneratedSecrets map[string]map[string]interface{} `yaml:"generatedSecrets"`
+	}
+	require.NoError(t, yaml.Unmarshal(raw, &values))
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "SCORE_SECRET_DB_PASSWORD", "valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": name, "key": "password"},
+		}},
+		{"name": "DB_HOST", "value": name},
+		{"name": "DB_PASSWORD", "valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": name, "key": "password"},
+		}},
+		{"name": "DB_URL", "value": fmt.Sprintf("postgres://%s:$(SCORE_SECRET_DB_PASSWORD)@%s:5432/%s", res.Outputs["username"], name, res.Outputs["database"])},
+	}, values.Containers["main"].Env)
+	// the chart generates the password, so that the values do not hold it
+	assert.Equal(t, map[string]interface{}{"generate": 16}, values.GeneratedSecrets[name]["password"])
+}
+
+func TestInitAndGenerate_with_envs(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--env", "prod"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", "envs", "prod", "dns.provisioners.yaml"), []byte(`
+- uri: template://prod-dns
+  type: dns
+  outputs: |
+    host: example.com
+`), 0644))
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--env", "prod", "-o", "values.prod.yaml"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "-o", "values.yaml"})
+	require.NoError(t, err)
+
+	// each environment has its own state and the environment provisioners take precedence
+	sd, ok, err := state.LoadStateDirectoryAt(filepath.Join(td, ".score-helm"), "prod")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "template://prod-dns", sd.State.Resources["dns.default#hello-world.dns"].ProvisionerUri)
+	sd, ok, err = state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "template://default-provisioners/dns", sd.State.Resources["dns.default#hello-world.dns"].ProvisionerUri)
+
+	raw, err := os.ReadFile(filepath.Join(td, "values.prod.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "host: example.com\n")
+	raw, err = os.ReadFile(filepath.Join(td, "values.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "host: example.com\n")
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--env", "staging"})
+	assert.EqualError(t, err, "state of environment 'staging' does not exist, please run \"init --env staging\" first")
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--env", "../staging"})
+	assert.EqualError(t, err, "failed to load existing state directory: environment name '../staging' is invalid, it must not be a path")
+}
+
+func TestInitAndGenerate_with_state_dir(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--state-dir", "deploy/state"})
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(td, ".score-helm"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--state-dir", "deploy/state"})
+	require.NoError(t, err)
+	sd, ok, err := state.LoadStateDirectoryAt(filepath.Join(td, "deploy", "state"), "")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Len(t, sd.State.Workloads, 1)
+}
+
+func TestInitAndGenerate_with_locked_state(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+
+	lockPath := filepath.Join(td, ".score-helm", state.LockFileName)
+	require.NoError(t, os.WriteFile(lockPath, []byte("pid 1 on elsewhere\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--lock-timeout", "200ms"})
+	assert.EqualError(t, err, "failed to load existing state directory: state is locked by pid 1 on elsewhere, gave up after 200ms: if no other score-helm command is running, remove '"+filepath.Join(".score-helm", state.LockFileName)+"'")
+
+	// a lock left behind by a process that did not exit cleanly is taken over
+	old := time.Now().Add(-state.StaleLockAge - time.Minute)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+	assert.NoFileExists(t, lockPath)
+}
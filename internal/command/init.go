@@ -19,16 +19,19 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/score-spec/score-go/framework"
 	"github.com/spf13/cobra"
 
+	default_provisioners "github.com/score-spec/score-helm/internal/provisioners/default"
 	"github.com/score-spec/score-helm/internal/state"
 )
 
 const (
-	initCmdFileFlag         = "file"
-	initCmdFileNoSampleFlag = "no-sample"
+	initCmdFileFlag                  = "file"
+	initCmdFileNoSampleFlag          = "no-sample"
+	initCmdNoDefaultProvisionersFlag = "no-default-provisioners"
 
 	DefaultScoreFileContent = `# Score provides a developer-centric and platform-agnostic
 # Workload specification to improve developer productivity and experience.
@@ -54,6 +57,10 @@ resources:
     type: dns
   route:
     type: route
+    params:
+      host: ${resources.dns.host}
+      path: /
+      port: 8080
 service:
   ports:
     www:
@@ -73,27 +80,52 @@ var initCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
-		sd, ok, err := state.LoadStateDirectory(".")
+		sd, err := newStateDirectory(cmd)
+		if err != nil {
+			return err
+		}
+		// the lock file is held next to the state file, so the directory is created before the lock is taken
+		if err := os.MkdirAll(sd.StatePath(), 0755); err != nil {
+			return fmt.Errorf("failed to create directory '%s': %w", sd.StatePath(), err)
+		}
+		lock, err := lockStateDirectory(cmd, sd)
 		if err != nil {
 			return fmt.Errorf("failed to load existing state directory: %w", err)
+		}
+		defer releaseLock(lock)
+
+		if existing, ok, err := loadStateDirectory(cmd); err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
 		} else if ok {
-			slog.Info("Found existing state directory", "dir", sd.Path)
+			sd = existing
+			slog.Info("Found existing state directory", "dir", sd.StatePath())
 		} else {
-			slog.Info("Writing new state directory", "dir", state.DefaultRelativeStateDirectory)
-			sd = &state.StateDirectory{
-				Path: state.DefaultRelativeStateDirectory,
-				State: state.State{
-					Workloads:   map[string]framework.ScoreWorkloadState[state.WorkloadExtras]{},
-					Resources:   map[framework.ResourceUid]framework.ScoreResourceState[state.ResourceExtras]{},
-					SharedState: map[string]interface{}{},
-				},
+			sd.State = state.State{
+				Workloads:   map[string]framework.ScoreWorkloadState[state.WorkloadExtras]{},
+				Resources:   map[framework.ResourceUid]framework.ScoreResourceState[state.ResourceExtras]{},
+				SharedState: map[string]interface{}{},
 			}
-			slog.Info("Writing new state directory", "dir", sd.Path)
+			slog.Info("Writing new state directory", "dir", sd.StatePath())
 			if err := sd.Persist(); err != nil {
 				return fmt.Errorf("failed to persist new state directory: %w", err)
 			}
 		}
 
+		if v, _ := cmd.Flags().GetBool(initCmdNoDefaultProvisionersFlag); v {
+			slog.Info("Skipping creation of default provisioners file since it is disabled")
+		} else {
+			dst := filepath.Join(sd.Path, default_provisioners.DefaultProvisionersFileName)
+			if _, err := os.Stat(dst); err == nil {
+				slog.Info("Skipping creation of default provisioners file since it already exists", "file", dst)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to check for existing default provisioners file: %w", err)
+			} else if err := os.WriteFile(dst, []byte(default_provisioners.DefaultProvisioners), 0644); err != nil {
+				return fmt.Errorf("failed to write default provisioners file: %w", err)
+			} else {
+				slog.Info("Created default provisioners file", "file", dst)
+			}
+		}
+
 		initCmdScoreFile, _ := cmd.Flags().GetString(initCmdFileFlag)
 		if _, err := os.Stat(initCmdScoreFile); err != nil {
 			if v, _ := cmd.Flags().GetBool(initCmdFileNoSampleFlag); v {
@@ -118,5 +150,6 @@ var initCmd = &cobra.Command{
 func init() {
 	initCmd.Flags().StringP(initCmdFileFlag, "f", "score.yaml", "The score file to initialize")
 	initCmd.Flags().Bool(initCmdFileNoSampleFlag, false, "Disable generation of the sample score file")
+	initCmd.Flags().Bool(initCmdNoDefaultProvisionersFlag, false, "Disable generation of the default provisioners file")
 	rootCmd.AddCommand(initCmd)
 }
@@ -17,6 +17,7 @@ package command
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -41,6 +42,9 @@ func TestInitNominal(t *testing.T) {
 	assert.Equal(t, "", stdout)
 	assert.NotEqual(t, "", strings.TrimSpace(stderr))
 
+	_, err = os.Stat(filepath.Join(state.DefaultRelativeStateDirectory, "zz-default.provisioners.yaml"))
+	assert.NoError(t, err)
+
 	raw, err := os.ReadFile("score.yaml")
 	require.NoError(t, err)
 	assert.Contains(t, string(raw), "name: hello-world")
@@ -62,9 +66,27 @@ func TestInitNominal(t *testing.T) {
 		assert.Contains(t, sd.State.Resources, framework.ResourceUid("dns.default#hello-world.dns"))
 		assert.Contains(t, sd.State.Resources, framework.ResourceUid("route.default#hello-world.route"))
 		assert.Equal(t, map[string]interface{}{}, sd.State.SharedState)
+		assert.Equal(t, "template://default-provisioners/postgres", sd.State.Resources["postgres.default#hello-world.db"].ProvisionerUri)
+		assert.Equal(t, "template://default-provisioners/dns", sd.State.Resources["dns.default#hello-world.dns"].ProvisionerUri)
+		assert.Equal(t, "template://default-provisioners/route", sd.State.Resources["route.default#hello-world.route"].ProvisionerUri)
 	}
 }
 
+func TestInitNoDefaultProvisioners(t *testing.T) {
+	td := t.TempDir()
+
+	wd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(td))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-default-provisioners"})
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(state.DefaultRelativeStateDirectory, "zz-default.provisioners.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
 func TestInitNominal_run_twice(t *testing.T) {
 	td := t.TempDir()
 
@@ -100,3 +122,16 @@ func TestInitNominal_run_twice(t *testing.T) {
 		assert.Equal(t, map[string]interface{}{}, sd.State.SharedState)
 	}
 }
+
+func TestInit_with_locked_state(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+	lockPath := filepath.Join(td, state.DefaultRelativeStateDirectory, state.LockFileName)
+	assert.NoFileExists(t, lockPath)
+
+	require.NoError(t, os.WriteFile(lockPath, []byte("pid 1 on elsewhere\n"), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample", "--lock-timeout", "200ms"})
+	assert.EqualError(t, err, "failed to load existing state directory: state is locked by pid 1 on elsewhere, gave up after 200ms: if no other score-helm command is running, remove '"+filepath.Join(state.DefaultRelativeStateDirectory, state.LockFileName)+"'")
+	assert.FileExists(t, lockPath)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/score-spec/score-go/framework"
+	"github.com/spf13/cobra"
+)
+
+const (
+	resourcesListCmdFormatFlag       = "format"
+	resourcesGetOutputsCmdFormatFlag = "format"
+)
+
+var resourcesCmd = &cobra.Command{
+	Use:   "resources",
+	Short: "Inspect the provisioned resources in the state",
+	Args:  cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+}
+
+// resourceView is the json form of a resource printed by the resources commands.
+type resourceView struct {
+	Uid            framework.ResourceUid  `json:"uid"`
+	Type           string                 `json:"type"`
+	Class          string                 `json:"class"`
+	Id             string                 `json:"id"`
+	SourceWorkload string                 `json:"source_workload"`
+	Consumers      []string               `json:"consumers"`
+	Provisioner    string                 `json:"provisioner"`
+	Params         map[string]interface{} `json:"params"`
+	State          map[string]interface{} `json:"state"`
+	Outputs        map[string]interface{} `json:"outputs"`
+}
+
+var resourcesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the resources in the state with their source workload, the workloads that use them, their provisioner, and outputs",
+	Example: `
+  # Print the resources as a table
+  score-helm resources list
+
+  # Print the resources with their params, state, and outputs as json
+  score-helm resources list --format json`,
+	Args: cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, _ := cmd.Flags().GetString(resourcesListCmdFormatFlag)
+		if format != "table" && format != "json" {
+			return fmt.Errorf("--%s '%s' is invalid, expected 'table' or 'json'", resourcesListCmdFormatFlag, format)
+		}
+
+		sd, ok, err := loadStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		} else if !ok {
+			return stateDirectoryNotFoundError(cmd)
+		}
+
+		resUids := slices.Sorted(maps.Keys(sd.State.Resources))
+		if format == "json" {
+			views := make([]resourceView, 0, len(resUids))
+			for _, resUid := range resUids {
+				res := sd.State.Resources[resUid]
+				views = append(views, resourceView{
+					Uid:            resUid,
+					Type:           res.Type,
+					Class:          res.Class,
+					Id:             res.Id,
+					SourceWorkload: res.SourceWorkload,
+					Consumers:      res.Extras.Consumers,
+					Provisioner:    res.ProvisionerUri,
+					Params:         res.Params,
+					State:          res.State,
+					Outputs:        res.Outputs,
+				})
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(views)
+		}
+
+		tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "UID\tSOURCE WORKLOAD\tCONSUMERS\tPROVISIONER\tOUTPUTS")
+		for _, resUid := range resUids {
+			res := sd.State.Resources[resUid]
+			provisioner := res.ProvisionerUri
+			if provisioner == "" {
+				provisioner = "-"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", resUid, res.SourceWorkload, strings.Join(res.Extras.Consumers, ","), provisioner, strings.Join(slices.Sorted(maps.Keys(res.Outputs)), ","))
+		}
+		return tw.Flush()
+	},
+}
+
+var resourcesGetOutputsCmd = &cobra.Command{
+	Use:   "get-outputs UID",
+	Short: "Print the outputs of a provisioned resource as json or with a Go template",
+	Long: `Print the outputs of a provisioned resource as json, or with a Go template given with --format. The template
+has access to the outputs and the sprig functions. Outputs that are resolved lazily, such as those of the environment
+resource, are not held in the state and are not printed.`,
+	Example: `
+  # Print the outputs of a resource as json
+  score-helm resources get-outputs 'postgres.default#hello-world.db'
+
+  # Print the host of a resource
+  score-helm resources get-outputs 'dns.default#hello-world.dns' --format '{{ .host }}'`,
+	Args: cobra.ExactArgs(1),
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		var tmpl *template.Template
+		if format, _ := cmd.Flags().GetString(resourcesGetOutputsCmdFormatFlag); format != "" {
+			var err error
+			if tmpl, err = template.New("").Funcs(sprig.TxtFuncMap()).Parse(format); err != nil {
+				return fmt.Errorf("--%s is invalid: %w", resourcesGetOutputsCmdFormatFlag, err)
+			}
+		}
+
+		sd, ok, err := loadStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		} else if !ok {
+			return stateDirectoryNotFoundError(cmd)
+		}
+
+		res, ok := sd.State.Resources[framework.ResourceUid(args[0])]
+		if !ok {
+			return fmt.Errorf("resource '%s' does not exist", args[0])
+		}
+		outputs := res.Outputs
+		if outputs == nil {
+			outputs = make(map[string]interface{})
+		}
+
+		if tmpl != nil {
+			if err := tmpl.Execute(cmd.OutOrStdout(), outputs); err != nil {
+				return fmt.Errorf("failed to execute --%s: %w", resourcesGetOutputsCmdFormatFlag, err)
+			}
+			return nil
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(outputs)
+	},
+}
+
+func init() {
+	resourcesListCmd.Flags().String(resourcesListCmdFormatFlag, "table", "The output format, either 'table' or 'json'")
+	resourcesGetOutputsCmd.Flags().String(resourcesGetOutputsCmdFormatFlag, "", "An optional Go template to print the outputs with instead of json")
+	resourcesCmd.AddCommand(resourcesListCmd)
+	resourcesCmd.AddCommand(resourcesGetOutputsCmd)
+	rootCmd.AddCommand(resourcesCmd)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesListWithoutInit(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "list"})
+	assert.EqualError(t, err, "state directory does not exist, please run \"init\" first")
+}
+
+func TestResourcesList(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "list"})
+	require.NoError(t, err)
+	assert.Equal(t, `UID                         SOURCE WORKLOAD  CONSUMERS     PROVISIONER                               OUTPUTS
+postgres.default#shared-db  first            first,second  template://default-provisioners/postgres  database,host,name,port,username
+redis.default#first.cache   first            first         template://default-provisioners/redis     host,port,username
+redis.default#second.cache  second           second        template://default-provisioners/redis     host,port,username
+`, stdout)
+}
+
+func TestResourcesListJson(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "list", "--format", "json"})
+	require.NoError(t, err)
+	var views []resourceView
+	require.NoError(t, json.Unmarshal([]byte(stdout), &views))
+	require.Len(t, views, 3)
+	assert.Equal(t, "postgres.default#shared-db", string(views[0].Uid))
+	assert.Equal(t, "shared-db", views[0].Id)
+	assert.Equal(t, "first", views[0].SourceWorkload)
+	assert.Equal(t, []string{"first", "second"}, views[0].Consumers)
+	assert.NotEmpty(t, views[0].State)
+	assert.NotEmpty(t, views[0].Outputs["host"])
+}
+
+func TestResourcesListInvalidFormat(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "list", "--format", "yaml"})
+	assert.EqualError(t, err, "--format 'yaml' is invalid, expected 'table' or 'json'")
+}
+
+func TestResourcesGetOutputs(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "get-outputs", "redis.default#first.cache"})
+	require.NoError(t, err)
+	var outputs map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &outputs))
+	assert.Equal(t, float64(6379), outputs["port"])
+
+	stdout, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "get-outputs", "redis.default#first.cache", "--format", "{{ .host }}:{{ .port }}"})
+	require.NoError(t, err)
+	assert.Equal(t, outputs["host"].(string)+":6379", stdout)
+}
+
+func TestResourcesGetOutputsUnknown(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "get-outputs", "redis.default#third.cache"})
+	assert.EqualError(t, err, "resource 'redis.default#third.cache' does not exist")
+}
+
+func TestResourcesGetOutputsInvalidFormat(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"resources", "get-outputs", "redis.default#first.cache", "--format", "{{ .host"})
+	assert.ErrorContains(t, err, "--format is invalid: ")
+}
@@ -15,11 +15,21 @@
 package command
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 	"github.com/spf13/cobra"
+	"github.com/score-spec/score-helm/internal/state"
 	"github.com/score-spec/score-helm/internal/version"
 )
 
+const (
+	rootCmdStateDirFlag    = "state-dir"
+	rootCmdEnvFlag         = "env"
+	rootCmdLockTimeoutFlag = "lock-timeout"
+)
+
 var ScoreImplementationName = "score-helm"
 
 var rootCmd = &cobra.Command{
@@ -36,7 +46,71 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// newStateDirectory returns an empty state directory at the location selected by the --state-dir and --env flags.
+func newStateDirectory(cmd *cobra.Command) (*state.StateDirectory, error) {
+	path, _ := cmd.Flags().GetString(rootCmdStateDirFlag)
+	env, _ := cmd.Flags().GetString(rootCmdEnvFlag)
+	if err := state.ValidateEnv(env); err != nil {
+		return nil, err
+	}
+	return &state.StateDirectory{Path: path, Env: env}, nil
+}
+
+// loadStateDirectory loads the state directory selected by the --state-dir and --env flags.
+func loadStateDirectory(cmd *cobra.Command) (*state.StateDirectory, bool, error) {
+	path, _ := cmd.Flags().GetString(rootCmdStateDirFlag)
+	env, _ := cmd.Flags().GetString(rootCmdEnvFlag)
+	return state.LoadStateDirectoryAt(path, env)
+}
+
+// loadAndLockStateDirectory loads the state directory like loadStateDirectory while holding the lock on its state, so
+// that other commands do not change the state until the returned lock is released.
+func loadAndLockStateDirectory(cmd *cobra.Command) (*state.StateDirectory, *state.Lock, bool, error) {
+	sd, err := newStateDirectory(cmd)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if _, err := os.Stat(sd.StatePath()); errors.Is(err, os.ErrNotExist) {
+		return nil, nil, false, nil
+	}
+	lock, err := lockStateDirectory(cmd, sd)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	sd, ok, err := loadStateDirectory(cmd)
+	if err != nil || !ok {
+		_ = lock.Release()
+		return nil, nil, ok, err
+	}
+	return sd, lock, true, nil
+}
+
+// lockStateDirectory takes the lock on the state of the state directory, waiting for up to --lock-timeout.
+func lockStateDirectory(cmd *cobra.Command, sd *state.StateDirectory) (*state.Lock, error) {
+	timeout, _ := cmd.Flags().GetDuration(rootCmdLockTimeoutFlag)
+	return sd.Lock(timeout)
+}
+
+// releaseLock releases a lock taken by loadAndLockStateDirectory or lockStateDirectory, logging rather than failing the command if the lock
+// file cannot be removed.
+func releaseLock(lock *state.Lock) {
+	if err := lock.Release(); err != nil {
+		slog.Warn(err.Error())
+	}
+}
+
+// stateDirectoryNotFoundError is returned by commands that need the state written by init.
+func stateDirectoryNotFoundError(cmd *cobra.Command) error {
+	if env, _ := cmd.Flags().GetString(rootCmdEnvFlag); env != "" {
+		return fmt.Errorf("state of environment '%s' does not exist, please run \"init --env %s\" first", env, env)
+	}
+	return fmt.Errorf("state directory does not exist, please run \"init\" first")
+}
+
 func init() {
+	rootCmd.PersistentFlags().String(rootCmdStateDirFlag, state.DefaultRelativeStateDirectory, "The state directory holding the state, provisioners, and values template")
+	rootCmd.PersistentFlags().String(rootCmdEnvFlag, "", "An optional environment with its own state, provisioners, and values template under envs/<env> in the state directory")
+	rootCmd.PersistentFlags().Duration(rootCmdLockTimeoutFlag, state.DefaultLockTimeout, "How long to wait for another command to release the lock on the state")
 	rootCmd.Version = version.BuildVersionString()
 	rootCmd.SetVersionTemplate(`{{with .Name}}{{printf "%s " .}}{{end}}{{printf "%s" .Version}}
 `)
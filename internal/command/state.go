@@ -0,0 +1,66 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect the state",
+	Args:  cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the state, including the workloads, resources, and shared state",
+	Long: `Print the state, including the workloads, resources, and shared state. The resource outputs may hold
+credentials, so take care where the output is written.`,
+	Args: cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		sd, ok, err := loadStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		} else if !ok {
+			return stateDirectoryNotFoundError(cmd)
+		}
+
+		enc := yaml.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent(2)
+		if err := enc.Encode(sd.State); err != nil {
+			return fmt.Errorf("failed to encode state: %w", err)
+		}
+		return enc.Close()
+	},
+}
+
+func init() {
+	stateCmd.AddCommand(stateShowCmd)
+	rootCmd.AddCommand(stateCmd)
+}
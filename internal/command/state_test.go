@@ -0,0 +1,96 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/state"
+)
+
+func TestStateShowWithoutInit(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"state", "show", "--env", "dev"})
+	assert.EqualError(t, err, "state of environment 'dev' does not exist, please run \"init --env dev\" first")
+}
+
+func TestStateShow(t *testing.T) {
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"state", "show"})
+	require.NoError(t, err)
+	var shown state.State
+	require.NoError(t, yaml.Unmarshal([]byte(stdout), &shown))
+	sd, _, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	assert.Equal(t, sd.State.Resources["dns.default#hello-world.dns"].Outputs, shown.Resources["dns.default#hello-world.dns"].Outputs)
+	assert.Contains(t, shown.Workloads, "hello-world")
+}
+
+func TestStateMigratedFromUnversionedFile(t *testing.T) {
+	td := changeToTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(td, ".score-helm"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", state.FileName), []byte(`workloads: {}
+resources: {}
+shared_state: {}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "score.yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+`), 0644))
+
+	sd, ok, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, state.CurrentVersion, sd.State.Extras.Version)
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(td, ".score-helm", state.FileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "\nversion: 1\n")
+	sd, _, err = state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	assert.Contains(t, sd.State.Workloads, "example")
+}
+
+func TestStateFromNewerVersion(t *testing.T) {
+	td := changeToTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(td, ".score-helm"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(td, ".score-helm", state.FileName), []byte(`workloads: {}
+resources: {}
+shared_state: {}
+some_future_field: true
+version: 999
+`), 0644))
+
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"state", "show"})
+	assert.EqualError(t, err, "failed to load existing state directory: state file couldn't be decoded: state file has version 999 but this version of score-helm only supports up to version 1, please upgrade score-helm")
+}
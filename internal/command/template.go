@@ -0,0 +1,135 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+
+	"github.com/score-spec/score-helm/internal/chart"
+	"github.com/score-spec/score-helm/internal/convert"
+)
+
+const (
+	templateCmdChartFlag       = "chart"
+	templateCmdValuesFlag      = "values"
+	templateCmdSetFlag         = "set"
+	templateCmdReleaseNameFlag = "release-name"
+	templateCmdNamespaceFlag   = "namespace"
+
+	defaultReleaseName = "release-name"
+	defaultValuesFile  = "values.yaml"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Render the Kubernetes manifests from the values file and a Helm chart",
+	Long: `Render the Kubernetes manifests from the values file and a Helm chart in the same way as 'helm template', without
+needing the helm binary or access to a cluster. The chart embedded in score-helm is used unless --chart is set.`,
+	Example: `
+  # Render the values file written by generate with the embedded chart
+  score-helm template
+
+  # Render with a custom chart and extra values
+  score-helm template --chart ./chart --values values.yaml --values overrides.yaml`,
+	Args: cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		sd, stateOk, err := loadStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		}
+
+		releaseName, _ := cmd.Flags().GetString(templateCmdReleaseNameFlag)
+		if releaseName == "" {
+			// A single workload is named after the release, so default the release name to the workload name.
+			releaseName = defaultReleaseName
+			if stateOk && len(sd.State.Workloads) == 1 {
+				releaseName = slices.Collect(maps.Keys(sd.State.Workloads))[0]
+			}
+		}
+		namespace, _ := cmd.Flags().GetString(templateCmdNamespaceFlag)
+
+		valueFiles, _ := cmd.Flags().GetStringArray(templateCmdValuesFlag)
+		if len(valueFiles) == 0 {
+			valueFiles = []string{defaultValuesFile}
+		}
+		setValues, _ := cmd.Flags().GetStringArray(templateCmdSetFlag)
+		valueOpts := &values.Options{ValueFiles: valueFiles, Values: setValues}
+		vals, err := valueOpts.MergeValues(getter.Providers{})
+		if err != nil {
+			return fmt.Errorf("failed to load values: %w", err)
+		}
+
+		chartPath, _ := cmd.Flags().GetString(templateCmdChartFlag)
+		var chrt *helmchart.Chart
+		if chartPath != "" {
+			chrt, err = chart.LoadDirectory(chartPath)
+		} else {
+			// The embedded chart cannot hold the subcharts that provisioners add as chart dependencies, and rendering
+			// without them would silently leave out the resources they deploy.
+			if stateOk {
+				dependencies, err := convert.Dependencies(&sd.State, slices.Sorted(maps.Keys(sd.State.Workloads)))
+				if err != nil {
+					return fmt.Errorf("failed to collect chart dependencies: %w", err)
+				} else if len(dependencies) > 0 {
+					names := make([]string, 0, len(dependencies))
+					for _, dependency := range dependencies {
+						names = append(names, dependency.Key())
+					}
+					return fmt.Errorf(
+						"the embedded chart cannot render the chart dependencies %s added by provisioners: write the chart with 'generate --chart <dir>', run 'helm dependency build <dir>', and pass --chart <dir>",
+						strings.Join(names, ", "),
+					)
+				}
+			}
+			// The embedded chart is named after the release so that a single workload is named after the release.
+			chrt, err = chart.Load(chart.NewMetadata(releaseName, "0.1.0", ""))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load chart: %w", err)
+		}
+		slog.Info(fmt.Sprintf("Rendering chart '%s' as release '%s'", chrt.Name(), releaseName))
+
+		manifests, err := chart.Render(cmd.Context(), chrt, vals, releaseName, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to render chart: %w", err)
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), manifests)
+		return nil
+	},
+}
+
+func init() {
+	templateCmd.Flags().String(templateCmdChartFlag, "", "An optional chart directory or archive to render instead of the embedded chart")
+	templateCmd.Flags().StringArrayP(templateCmdValuesFlag, "f", []string{}, "The values files to render the chart with (default values.yaml)")
+	templateCmd.Flags().StringArray(templateCmdSetFlag, []string{}, "An optional set of path=value overrides of the values, as with helm --set")
+	templateCmd.Flags().String(templateCmdReleaseNameFlag, "", "The release name, defaults to the workload name when the project has a single workload or 'release-name' otherwise")
+	templateCmd.Flags().StringP(templateCmdNamespaceFlag, "n", "default", "The release namespace")
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTemplateWithoutValues(t *testing.T) {
+	_ = changeToTempDir(t)
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"template"})
+	assert.ErrorContains(t, err, "failed to load values")
+	assert.Equal(t, "", stdout)
+}
+
+func TestInitGenerateAndTemplate_with_embedded_chart(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+	assert.NoError(t, os.WriteFile("score.yaml", []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+    variables:
+      GREETING: "a&b <c>"
+    files:
+      /etc/config:
+        content: hello
+        mode: "644"
+service:
+  ports:
+    web:
+      port: 80
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml"})
+	require.NoError(t, err)
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"template"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout, `# Source: example/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example
+`)
+	assert.Contains(t, stdout, `          image: "busybox"
+          env:
+            - name: GREETING
+              value: a&b <c>
+`)
+	assert.Contains(t, stdout, `kind: ConfigMap
+metadata:
+  name: example-main-files
+`)
+	assert.Contains(t, stdout, `            items:
+            - key: file-0
+              mode: 420
+              path: file-0
+`)
+	assert.Contains(t, stdout, `kind: Service
+metadata:
+  name: example
+`)
+
+	stdout, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{
+		"template", "--release-name", "other", "--namespace", "ns", "--set", "containers.main.image.name=nginx",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "  name: other\n")
+	assert.Contains(t, stdout, `image: "nginx"`)
+}
+
+func TestInitGenerateAndTemplate_with_chart_directory(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init"})
+	require.NoError(t, err)
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--chart", "chart"})
+	require.NoError(t, err)
+
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"template", "--chart", "chart", "-f", "values.yaml"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "# Source: hello-world/templates/deployment.yaml\n")
+	assert.Contains(t, stdout, "kind: StatefulSet\n")
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"template", "--chart", "missing"})
+	assert.ErrorContains(t, err, "failed to load chart")
+}
+
+func TestInitGenerateAndTemplate_service_name_matches_workload_services(t *testing.T) {
+	// the service-port provisioner reads the hostname from the service_name of the workload services
+	for _, scoreFiles := range [][]string{{"example.yaml"}, {"example.yaml", "other.yaml"}} {
+		t.Run(strings.Join(scoreFiles, ","), func(t *testing.T) {
+			_ = changeToTempDir(t)
+			_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile("example.yaml", []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+    variables:
+      SELF_HOST: ${resources.self.hostname}
+service:
+  ports:
+    web:
+      port: 80
+resources:
+  self:
+    type: service-port
+    params:
+      workload: example
+      port: web
+`), 0644))
+			require.NoError(t, os.WriteFile("other.yaml", []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: other
+containers:
+  main:
+    image: busybox
+`), 0644))
+			_, _, err = executeAndResetCommand(context.Background(), rootCmd, append([]string{"generate"}, scoreFiles...))
+			require.NoError(t, err)
+
+			stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"template", "--release-name", "my-release"})
+			require.NoError(t, err)
+
+			var serviceNames []string
+			var selfHost string
+			dec := yaml.NewDecoder(strings.NewReader(stdout))
+			for {
+				var doc struct {
+					Kind     string `yaml:"kind"`
+					Metadata struct {
+						Name string `yaml:"name"`
+					} `yaml:"metadata"`
+					Spec struct {
+						Template struct {
+							Spec struct {
+								Containers []struct {
+									Env []struct {
+										Name  string `yaml:"name"`
+										Value string `yaml:"value"`
+									} `yaml:"env"`
+								} `yaml:"containers"`
+							} `yaml:"spec"`
+						} `yaml:"template"`
+					} `yaml:"spec"`
+				}
+				if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+					break
+				} else {
+					require.NoError(t, err)
+				}
+				switch doc.Kind {
+				case "Service":
+					serviceNames = append(serviceNames, doc.Metadata.Name)
+				case "Deployment":
+					for _, container := range doc.Spec.Template.Spec.Containers {
+						for _, env := range container.Env {
+							if env.Name == "SELF_HOST" {
+								selfHost = env.Value
+							}
+						}
+					}
+				}
+			}
+			assert.Equal(t, "example", selfHost)
+			assert.Equal(t, []string{selfHost}, serviceNames)
+		})
+	}
+}
+
+func TestInitGenerateAndTemplate_with_chart_dependencies(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-default-provisioners"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(".score-helm", "00-custom.provisioners.yaml"), []byte(`
+- uri: template://subchart-postgres
+  type: postgres
+  outputs: |
+    host: pg-{{ .SourceWorkload }}
+  dependencies: |
+    - name: postgresql
+      version: 16.x.x
+      repository: oci://registry-1.docker.io/bitnamicharts
+      alias: db
+`), 0644))
+	require.NoError(t, os.WriteFile("score.yaml", []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: example
+containers:
+  main:
+    image: busybox
+    variables:
+      DB_HOST: ${resources.db.host}
+resources:
+  db:
+    type: postgres
+`), 0644))
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "score.yaml", "--chart", "chart"})
+	require.NoError(t, err)
+
+	// the embedded chart cannot hold the subchart, so rendering it would leave out the database
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"template"})
+	assert.EqualError(t, err, "the embedded chart cannot render the chart dependencies db added by provisioners: write the chart with 'generate --chart <dir>', run 'helm dependency build <dir>', and pass --chart <dir>")
+	assert.Equal(t, "", stdout)
+
+	// and the written chart needs its dependencies to be fetched first
+	stdout, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"template", "--chart", "chart"})
+	assert.EqualError(t, err, "failed to render chart: run 'helm dependency build' on the chart first: found in Chart.yaml, but missing in charts/ directory: postgresql")
+	assert.Equal(t, "", stdout)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/score-spec/score-helm/internal/state"
+)
+
+var workloadsCmd = &cobra.Command{
+	Use:   "workloads",
+	Short: "Inspect and remove the workloads in the state",
+	Args:  cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+}
+
+var workloadsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the workloads in the state with their Score file and resources",
+	Args:  cobra.NoArgs,
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		sd, ok, err := loadStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		} else if !ok {
+			return stateDirectoryNotFoundError(cmd)
+		}
+
+		tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "NAME\tFILE\tRESOURCES")
+		for _, name := range slices.Sorted(maps.Keys(sd.State.Workloads)) {
+			workload := sd.State.Workloads[name]
+			file := "-"
+			if workload.File != nil {
+				file = *workload.File
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\n", name, file, len(workload.Spec.Resources))
+		}
+		return tw.Flush()
+	},
+}
+
+var workloadsRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a workload from the state along with the resources that no other workload uses",
+	Long: `Remove a workload from the state along with the resources that no other workload uses. The values file is not
+changed, so run generate again afterwards to write the values of the remaining workloads.`,
+	Args: cobra.ExactArgs(1),
+	CompletionOptions: cobra.CompletionOptions{
+		HiddenDefaultCmd: true,
+	},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		sd, lock, ok, err := loadAndLockStateDirectory(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to load existing state directory: %w", err)
+		} else if !ok {
+			return stateDirectoryNotFoundError(cmd)
+		}
+		defer releaseLock(lock)
+
+		currentState, removedResources, err := state.WithoutWorkload(&sd.State, args[0])
+		if err != nil {
+			return err
+		}
+		for _, resUid := range removedResources {
+			slog.Info(fmt.Sprintf("Removed resource '%s' which is no longer used", resUid))
+		}
+
+		sd.State = *currentState
+		if err := sd.Persist(); err != nil {
+			return fmt.Errorf("failed to persist state file: %w", err)
+		}
+		slog.Info(fmt.Sprintf("Removed workload '%s'", args[0]))
+		return nil
+	},
+}
+
+func init() {
+	workloadsCmd.AddCommand(workloadsListCmd)
+	workloadsCmd.AddCommand(workloadsRemoveCmd)
+	rootCmd.AddCommand(workloadsCmd)
+}
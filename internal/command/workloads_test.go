@@ -0,0 +1,95 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/score-spec/score-go/framework"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/score-spec/score-helm/internal/state"
+)
+
+func TestWorkloadsListWithoutInit(t *testing.T) {
+	_ = changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"workloads", "list"})
+	assert.EqualError(t, err, "state directory does not exist, please run \"init\" first")
+}
+
+func setupTwoWorkloads(t *testing.T) string {
+	t.Helper()
+	td := changeToTempDir(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"init", "--no-sample"})
+	require.NoError(t, err)
+	for _, name := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(td, name+".yaml"), []byte(`
+apiVersion: score.dev/v1b1
+metadata:
+  name: `+name+`
+containers:
+  main:
+    image: busybox
+resources:
+  cache:
+    type: redis
+  db:
+    type: postgres
+    id: shared-db
+`), 0644))
+	}
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"generate", "first.yaml", "second.yaml"})
+	require.NoError(t, err)
+	return td
+}
+
+func TestWorkloadsList(t *testing.T) {
+	_ = setupTwoWorkloads(t)
+	stdout, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"workloads", "list"})
+	require.NoError(t, err)
+	assert.Equal(t, `NAME    FILE         RESOURCES
+first   first.yaml   2
+second  second.yaml  2
+`, stdout)
+}
+
+func TestWorkloadsRemove(t *testing.T) {
+	td := setupTwoWorkloads(t)
+	_, _, err := executeAndResetCommand(context.Background(), rootCmd, []string{"workloads", "remove", "first"})
+	require.NoError(t, err)
+
+	sd, ok, err := state.LoadStateDirectory(td)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"second"}, slices.Sorted(maps.Keys(sd.State.Workloads)))
+	// the shared resource is kept while the resource of the removed workload is pruned
+	assert.Equal(t, []framework.ResourceUid{
+		"postgres.default#shared-db",
+		"redis.default#second.cache",
+	}, slices.Sorted(maps.Keys(sd.State.Resources)))
+	// the shared resource is no longer used by, or sourced from, the removed workload
+	shared := sd.State.Resources["postgres.default#shared-db"]
+	assert.Equal(t, []string{"second"}, shared.Extras.Consumers)
+	assert.Equal(t, "second", shared.SourceWorkload)
+
+	_, _, err = executeAndResetCommand(context.Background(), rootCmd, []string{"workloads", "remove", "first"})
+	assert.EqualError(t, err, "workload 'first' does not exist")
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	scoretypes "github.com/score-spec/score-go/types"
+)
+
+// AnnotationPrefix is the prefix of the Score workload annotations that score-helm interprets as extensions to the
+// Score specification.
+const AnnotationPrefix = "score-helm.dev/"
+
+var (
+	probeNames   = []string{"livenessProbe", "readinessProbe"}
+	probeOptions = []string{"initialDelaySeconds", "periodSeconds", "timeoutSeconds", "successThreshold", "failureThreshold", "terminationGracePeriodSeconds"}
+)
+
+// parseProbeOptions reads the probe timing options from the workload annotations. These have the form
+// 'score-helm.dev/<container>.<livenessProbe|readinessProbe>.<option>' with a non-negative integer value. The result is
+// keyed by container name, then probe name, then option.
+func parseProbeOptions(spec scoretypes.Workload) (map[string]map[string]map[string]int, error) {
+	output := make(map[string]map[string]map[string]int)
+	annotations, _ := spec.Metadata["annotations"].(map[string]interface{})
+	for key, rawValue := range annotations {
+		name, ok := strings.CutPrefix(key, AnnotationPrefix)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(name, ".")
+		if len(parts) != 3 || !slices.Contains(probeNames, parts[1]) {
+			continue
+		}
+		containerName, probeName, option := parts[0], parts[1], parts[2]
+		container, ok := spec.Containers[containerName]
+		if !ok {
+			return nil, fmt.Errorf("annotation '%s': no container named '%s'", key, containerName)
+		}
+		if (probeName == "livenessProbe" && container.LivenessProbe == nil) || (probeName == "readinessProbe" && container.ReadinessProbe == nil) {
+			return nil, fmt.Errorf("annotation '%s': container '%s' has no %s", key, containerName, probeName)
+		}
+		if !slices.Contains(probeOptions, option) {
+			return nil, fmt.Errorf("annotation '%s': unknown probe option '%s', expected one of %v", key, option, probeOptions)
+		}
+		value, err := strconv.Atoi(fmt.Sprint(rawValue))
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("annotation '%s': expected a non-negative integer", key)
+		}
+		// Kubernetes rejects these on the api server, so report them here rather than at install time
+		if probeName == "readinessProbe" && option == "terminationGracePeriodSeconds" {
+			return nil, fmt.Errorf("annotation '%s': terminationGracePeriodSeconds cannot be set on a readinessProbe", key)
+		} else if probeName == "livenessProbe" && option == "successThreshold" && value != 1 {
+			return nil, fmt.Errorf("annotation '%s': successThreshold must be 1 for a livenessProbe", key)
+		}
+		if output[containerName] == nil {
+			output[containerName] = make(map[string]map[string]int)
+		}
+		if output[containerName][probeName] == nil {
+			output[containerName][probeName] = make(map[string]int)
+		}
+		output[containerName][probeName][option] = value
+	}
+	return output, nil
+}
+
+// parseMetadata returns the annotations and custom labels of the workload metadata as string maps. Annotations with
+// the score-helm prefix configure the conversion and are left out.
+func parseMetadata(spec scoretypes.Workload) (map[string]string, map[string]string, error) {
+	annotations := make(map[string]string)
+	rawAnnotations, _ := spec.Metadata["annotations"].(map[string]interface{})
+	for key, value := range rawAnnotations {
+		if !strings.HasPrefix(key, AnnotationPrefix) {
+			annotations[key] = fmt.Sprint(value)
+		}
+	}
+
+	labels := make(map[string]string)
+	if rawLabels, ok := spec.Metadata["labels"]; ok {
+		labelsMap, ok := rawLabels.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("metadata: labels: expected a map of strings")
+		}
+		for key, rawValue := range labelsMap {
+			value, ok := rawValue.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("metadata: labels: %s: expected a string", key)
+			}
+			labels[key] = value
+		}
+	}
+	return annotations, labels, nil
+}
@@ -17,24 +17,186 @@ package convert
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 
-	"github.com/Masterminds/sprig/v3"
+	"dario.cat/mergo"
 	"github.com/score-spec/score-go/framework"
 	scoretypes "github.com/score-spec/score-go/types"
+	"gopkg.in/yaml.v3"
 
+	"github.com/score-spec/score-helm/internal/extensions"
 	"github.com/score-spec/score-helm/internal/state"
 )
 
 type Data struct {
 	WorkloadName string
 	Spec         scoretypes.Workload
+	// SecretFiles holds the targets of the files in each container that interpolate resource outputs. These are
+	// written as Secrets rather than ConfigMaps since resource outputs commonly hold credentials.
+	SecretFiles map[string]map[string]bool
+	// SecretVariables holds the Kubernetes Secret key of each variable that is a secret resource output, keyed by
+	// container name then variable name. The value of these variables is left empty in the spec.
+	SecretVariables map[string]map[string]state.SecretKeyRef
+	// SecretEnvVars holds the env vars of each container that are read from Kubernetes Secrets for the variables that
+	// combine secret resource outputs with other content, keyed by container name. These must come before the
+	// variables in the env of the container, since the variables refer to them with $(NAME).
+	SecretEnvVars map[string][]SecretEnvVar
+	// Volumes holds the Kubernetes volume source of each volume mounted by the containers, keyed by volume name.
+	Volumes map[string]map[string]interface{}
+	// ProbeOptions holds the probe timing options set through workload annotations, keyed by container name, then
+	// probe name, then option.
+	ProbeOptions map[string]map[string]map[string]int
+	// Annotations holds the workload annotations, excluding the score-helm annotations that configure the conversion.
+	Annotations map[string]string
+	// Labels holds the custom labels from the workload metadata.
+	Labels map[string]string
+	// Extensions holds the Kubernetes-specific settings of the workload from the extensions file.
+	Extensions extensions.Workload
+	// Resources holds the provisioned resources of the workload keyed by resource name.
+	Resources map[string]Resource
+
+	resourceOutputs map[string]framework.OutputLookupFunc
+}
+
+// SecretEnvVar is an env var read from a Kubernetes Secret key. Variables that combine a secret resource output with
+// other content refer to it with $(NAME), which Kubernetes expands when it starts the container.
+type SecretEnvVar struct {
+	Name string
+	Ref  state.SecretKeyRef
+}
+
+// Resource is a provisioned resource of the workload.
+type Resource struct {
+	Type   string
+	Class  string
+	Id     string
+	Params map[string]interface{}
+	// Outputs holds the outputs returned by the provisioner. Outputs that are resolved lazily, such as those of the
+	// environment resource, are only available through Data.Output.
+	Outputs map[string]interface{}
+}
+
+// Output looks up a resource output by resource name and output key path, in the same way as a ${resources.<name>.<key>}
+// placeholder. This is available to values templates as {{ .Output "db" "host" }}.
+func (d Data) Output(resName string, keys ...string) (interface{}, error) {
+	lookup, ok := d.resourceOutputs[resName]
+	if !ok {
+		return nil, fmt.Errorf("no resource named '%s'", resName)
+	}
+	return lookup(keys...)
 }
 
+// Workload converts a single workload to a values file.
 func Workload(currentState *state.State, workloadName string) (string, error) {
+	return Workloads(currentState, []string{workloadName})
+}
+
+// Workloads converts the given workloads to a single values file. A single workload is written at the top level of the
+// values file, while multiple workloads are each written under 'workloads.<name>' in sorted order. The values fragments
+// of the resources used by the workloads are merged in at the top level, once per resource.
+func Workloads(currentState *state.State, workloadNames []string) (string, error) {
+	return WorkloadsWithTemplate(currentState, workloadNames, "")
+}
+
+// WorkloadsWithTemplate converts the given workloads in the same way as Workloads, but renders the values of each
+// workload with the given text/template rather than the default values template. The template is executed with the
+// Data of the workload and must produce a YAML mapping. An empty template uses the default values template.
+func WorkloadsWithTemplate(currentState *state.State, workloadNames []string, valuesTemplate string) (string, error) {
+	if valuesTemplate == "" {
+		valuesTemplate = defaultValuesTemplate
+	}
+	t, err := template.New("values").Funcs(valuesTemplateFuncs()).Parse(valuesTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse values template: %w", err)
+	}
+
+	workloadNames = slices.Sorted(slices.Values(workloadNames))
+	out := new(strings.Builder)
+	valuesFragments := make([]map[string]interface{}, 0)
+	seenResources := make(map[framework.ResourceUid]bool)
+	for _, workloadName := range workloadNames {
+		values, err := convertWorkload(currentState, workloadName, t)
+		if err != nil {
+			return "", err
+		}
+		if len(workloadNames) == 1 {
+			out.WriteString(values)
+		} else {
+			if out.Len() == 0 {
+				out.WriteString("workloads:\n")
+			}
+			out.WriteString("  " + workloadName + ":\n")
+			out.WriteString(indentLines(values, "    "))
+		}
+
+		spec := currentState.Workloads[workloadName].Spec
+		for _, resName := range slices.Sorted(maps.Keys(spec.Resources)) {
+			res := spec.Resources[resName]
+			resUid := framework.NewResourceUid(workloadName, resName, res.Type, res.Class, res.Id)
+			if resState := currentState.Resources[resUid]; !seenResources[resUid] && len(resState.Extras.Values) > 0 {
+				valuesFragments = append(valuesFragments, resState.Extras.Values)
+			}
+			seenResources[resUid] = true
+		}
+	}
+
+	values, err := mergeValuesFragments(out.String(), valuesFragments)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge resource values: %w", err)
+	}
+	return values, nil
+}
+
+// Dependencies returns the chart dependencies returned by the provisioners of the resources used by the given
+// workloads, in the same order as their values fragments are merged. A dependency shared by several resources is
+// returned once, while different dependencies under the same name or alias are an error.
+func Dependencies(currentState *state.State, workloadNames []string) ([]state.ChartDependency, error) {
+	out := make([]state.ChartDependency, 0)
+	owners := make([]framework.ResourceUid, 0)
+	indexes := make(map[string]int)
+	seenResources := make(map[framework.ResourceUid]bool)
+	for _, workloadName := range slices.Sorted(slices.Values(workloadNames)) {
+		spec := currentState.Workloads[workloadName].Spec
+		for _, resName := range slices.Sorted(maps.Keys(spec.Resources)) {
+			res := spec.Resources[resName]
+			resUid := framework.NewResourceUid(workloadName, resName, res.Type, res.Class, res.Id)
+			if seenResources[resUid] {
+				continue
+			}
+			seenResources[resUid] = true
+			for _, dependency := range currentState.Resources[resUid].Extras.Dependencies {
+				if i, ok := indexes[dependency.Key()]; !ok {
+					indexes[dependency.Key()] = len(out)
+					out = append(out, dependency)
+					owners = append(owners, resUid)
+				} else if out[i] != dependency {
+					return nil, fmt.Errorf("resource '%s': chart dependency '%s' conflicts with the one of resource '%s'", resUid, dependency.Key(), owners[i])
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// indentLines prefixes each non-empty line with the given indent.
+func indentLines(raw string, indent string) string {
+	lines := strings.SplitAfter(raw, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// convertWorkload renders the values of a single workload with the given values template.
+func convertWorkload(currentState *state.State, workloadName string, t *template.Template) (string, error) {
 	resOutputs, err := currentState.GetResourceOutputForWorkload(workloadName)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate outputs: %w", err)
@@ -42,19 +204,36 @@ func Workload(currentState *state.State, workloadName string) (string, error) {
 	sf := framework.BuildSubstitutionFunction(currentState.Workloads[workloadName].Spec.Metadata, resOutputs)
 
 	spec := currentState.Workloads[workloadName].Spec
+	secretOutputs := make(map[string]map[string]state.SecretKeyRef)
+	for resName, res := range spec.Resources {
+		resUid := framework.NewResourceUid(workloadName, resName, res.Type, res.Class, res.Id)
+		if secrets := currentState.Resources[resUid].Extras.Secrets; len(secrets) > 0 {
+			secretOutputs[resName] = secrets
+		}
+	}
+
 	containers := maps.Clone(spec.Containers)
+	secretFiles := make(map[string]map[string]bool)
+	secretVariables := make(map[string]map[string]state.SecretKeyRef)
+	secretEnvVars := make(map[string][]SecretEnvVar)
+	volumes := make(map[string]map[string]interface{})
 	for containerName, container := range containers {
-		if container.Variables, err = convertContainerVariables(container.Variables, sf); err != nil {
+		if container.Variables, secretVariables[containerName], secretEnvVars[containerName], err = convertContainerVariables(container.Variables, sf, secretOutputs); err != nil {
 			return "", fmt.Errorf("workload: %s: container: %s: variables: %w", workloadName, containerName, err)
 		}
 
-		if container.Files, err = convertContainerFiles(container.Files, currentState.Workloads[workloadName].File, sf); err != nil {
+		if container.Files, secretFiles[containerName], err = convertContainerFiles(container.Files, currentState.Workloads[workloadName].File, sf, secretOutputs); err != nil {
 			return "", fmt.Errorf("workload: %s: container: %s: files: %w", workloadName, containerName, err)
 		}
+
+		if container.Volumes, err = convertContainerVolumes(container.Volumes, resOutputs, volumes); err != nil {
+			return "", fmt.Errorf("workload: %s: container: %s: volumes: %w", workloadName, containerName, err)
+		}
 		containers[containerName] = container
 	}
 	spec.Containers = containers
 	resources := maps.Clone(spec.Resources)
+	provisionedResources := make(map[string]Resource, len(resources))
 	for resName, res := range resources {
 		resUid := framework.NewResourceUid(workloadName, resName, res.Type, res.Class, res.Id)
 		resState, ok := currentState.Resources[resUid]
@@ -65,15 +244,49 @@ func Workload(currentState *state.State, workloadName string) (string, error) {
 		res.Id = &resState.Id
 		res.Type = resState.Type
 		resources[resName] = res
+		provisionedResources[resName] = Resource{
+			Type:    resState.Type,
+			Class:   resState.Class,
+			Id:      resState.Id,
+			Params:  resState.Params,
+			Outputs: resState.Outputs,
+		}
 	}
 	spec.Resources = resources
 
+	probeOptions, err := parseProbeOptions(spec)
+	if err != nil {
+		return "", fmt.Errorf("workload: %s: %w", workloadName, err)
+	}
+	annotations, labels, err := parseMetadata(spec)
+	if err != nil {
+		return "", fmt.Errorf("workload: %s: %w", workloadName, err)
+	}
+	var workloadExtensions extensions.Workload
+	if e := currentState.Workloads[workloadName].Extras.Extensions; e != nil {
+		workloadExtensions = *e
+		if err := validateExtensions(spec, workloadExtensions); err != nil {
+			return "", fmt.Errorf("workload: %s: extensions: %w", workloadName, err)
+		}
+	}
+
 	// Convert the Score workload to a values file
 	data := Data{
-		WorkloadName: workloadName,
-		Spec:         spec,
+		WorkloadName:    workloadName,
+		Spec:            spec,
+		SecretFiles:     secretFiles,
+		SecretVariables: secretVariables,
+		SecretEnvVars:   secretEnvVars,
+		Volumes:         volumes,
+		ProbeOptions:    probeOptions,
+		Annotations:     annotations,
+		Labels:          labels,
+		Extensions:      workloadExtensions,
+		Resources:       provisionedResources,
+
+		resourceOutputs: resOutputs,
 	}
-	values, err := convertToValuesFile(data)
+	values, err := convertToValuesFile(t, data)
 	if err != nil {
 		return "", fmt.Errorf("workload: %s: failed to convert to values file: %w", workloadName, err)
 	}
@@ -82,8 +295,8 @@ func Workload(currentState *state.State, workloadName string) (string, error) {
 }
 
 // convertToValuesFile converts a Score workload to a values file
-func convertToValuesFile(data Data) (string, error) {
-	fileContent, err := generateValuesFile(data)
+func convertToValuesFile(t *template.Template, data Data) (string, error) {
+	fileContent, err := generateValuesFile(t, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate container app: %w", err)
 	}
@@ -91,35 +304,201 @@ func convertToValuesFile(data Data) (string, error) {
 	return fileContent, nil
 }
 
-func generateValuesFile(data Data) (string, error) {
-	t, err := template.New("").Funcs(sprig.FuncMap()).Parse(defaultValuesTemplate)
-	if err != nil {
-		return "", err
-	}
+func generateValuesFile(t *template.Template, data Data) (string, error) {
 	var buf bytes.Buffer
 
 	if err := t.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
+	// A custom values template may produce anything, so check that the output can be nested and merged as values
+	var out map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &out); err != nil {
+		return "", fmt.Errorf("the values template did not produce a YAML mapping: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// mergeValuesFragments deep merges the values fragments returned by resource provisioners into the rendered values
+// file. The values file is only re-encoded when there is something to merge.
+func mergeValuesFragments(values string, fragments []map[string]interface{}) (string, error) {
+	if len(fragments) == 0 {
+		return values, nil
+	}
+	var out map[string]interface{}
+	if err := yaml.Unmarshal([]byte(values), &out); err != nil {
+		return "", fmt.Errorf("failed to decode values: %w", err)
+	}
+	if out == nil {
+		out = make(map[string]interface{})
+	}
+	for _, fragment := range fragments {
+		if err := mergo.Merge(&out, fragment, mergo.WithOverride, mergo.WithAppendSlice); err != nil {
+			return "", err
+		}
+	}
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return "", fmt.Errorf("failed to encode values: %w", err)
+	}
 	return buf.String(), nil
 }
 
-func convertContainerVariables(input scoretypes.ContainerVariables, sf func(string) (string, error)) (map[string]string, error) {
+// validateExtensions checks that the extensions refer to containers and a service that the workload has.
+func validateExtensions(spec scoretypes.Workload, workloadExtensions extensions.Workload) error {
+	if err := workloadExtensions.Validate(); err != nil {
+		return err
+	}
+	for containerName := range workloadExtensions.Containers {
+		if _, ok := spec.Containers[containerName]; !ok {
+			return fmt.Errorf("containers: no container named '%s'", containerName)
+		}
+	}
+	if workloadExtensions.Service != nil && (spec.Service == nil || len(spec.Service.Ports) == 0) {
+		return fmt.Errorf("service: the workload has no service ports")
+	}
+	if workloadExtensions.Service != nil {
+		for portName := range workloadExtensions.Service.Ports {
+			if _, ok := spec.Service.Ports[portName]; !ok {
+				return fmt.Errorf("service: ports: no port named '%s'", portName)
+			}
+		}
+	}
+	return nil
+}
+
+// convertContainerVariables substitutes the placeholders in the container variables. A variable that is a single
+// reference to a secret resource output is returned as a Secret key instead, with an empty value, so that the secret
+// is not written to the values. A variable that combines secret outputs with other content refers to each of them
+// through an env var read from the Secret, which are returned in sorted order.
+func convertContainerVariables(input scoretypes.ContainerVariables, sf func(string) (string, error), secretOutputs map[string]map[string]state.SecretKeyRef) (map[string]string, map[string]state.SecretKeyRef, []SecretEnvVar, error) {
 	outMap := make(map[string]string, len(input))
+	secrets := make(map[string]state.SecretKeyRef)
+	envVars := make(map[string]SecretEnvVar)
 	for key, value := range input {
-		out, err := framework.SubstituteString(value, sf)
+		var refs []string
+		out, err := framework.SubstituteString(value, func(ref string) (string, error) {
+			parts := framework.SplitRefParts(ref)
+			if len(parts) < 3 || parts[0] != "resources" {
+				return sf(ref)
+			}
+			secretRef, ok := secretOutputs[parts[1]][parts[2]]
+			if !ok {
+				return sf(ref)
+			} else if len(parts) != 3 {
+				return "", fmt.Errorf("secret output '%s' cannot be indexed", ref)
+			}
+			if value == "${"+ref+"}" {
+				secrets[key] = secretRef
+				return "", nil
+			}
+			name := secretEnvVarName(parts[1], parts[2])
+			if _, ok := input[name]; ok {
+				return "", fmt.Errorf("secret output '%s' is read through the env var '%s', which conflicts with the variable of the same name", ref, name)
+			}
+			envVars[name] = SecretEnvVar{Name: name, Ref: secretRef}
+			// a placeholder that cannot occur in a variable is replaced after the rest of the value is escaped
+			refs = append(refs, name)
+			return fmt.Sprintf("\x00%d\x00", len(refs)-1), nil
+		})
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", key, err)
+			return nil, nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if len(refs) > 0 {
+			// Kubernetes reduces $$ to $ when it expands $(NAME) references, so the other content must be escaped
+			out = strings.ReplaceAll(out, "$", "$$")
+			for i, name := range refs {
+				out = strings.Replace(out, fmt.Sprintf("\x00%d\x00", i), "$("+name+")", 1)
+			}
 		}
 		outMap[key] = out
 	}
-	return outMap, nil
+	sortedEnvVars := make([]SecretEnvVar, 0, len(envVars))
+	for _, name := range slices.Sorted(maps.Keys(envVars)) {
+		sortedEnvVars = append(sortedEnvVars, envVars[name])
+	}
+	return outMap, secrets, sortedEnvVars, nil
 }
 
-func convertContainerFiles(input map[string]scoretypes.ContainerFile, scoreFile *string, sf func(string) (string, error)) (map[string]scoretypes.ContainerFile, error) {
+// secretEnvVarName returns the name of the env var that holds a secret resource output for the variables that combine
+// it with other content.
+func secretEnvVarName(resName string, outputName string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper("SCORE_SECRET_"+resName+"_"+outputName))
+}
+
+// convertContainerVolumes resolves the source of each volume through the 'source' output of the referenced resource,
+// which must be a Kubernetes volume source. The source of each returned volume is replaced with the volume name and
+// the resolved volume sources are added to the volumes map.
+func convertContainerVolumes(input scoretypes.ContainerVolumes, resOutputs map[string]framework.OutputLookupFunc, volumes map[string]map[string]interface{}) (scoretypes.ContainerVolumes, error) {
+	output := make(scoretypes.ContainerVolumes, len(input))
+	for target, volume := range input {
+		resName, ok := parseResourceReference(volume.Source)
+		if !ok {
+			return nil, fmt.Errorf("%s: source: expected a resource reference like '${resources.<name>}'", target)
+		}
+		lookup, ok := resOutputs[resName]
+		if !ok {
+			return nil, fmt.Errorf("%s: source: no known resource '%s'", target, resName)
+		}
+		rawSource, err := lookup("source")
+		if err != nil {
+			return nil, fmt.Errorf("%s: source: resource '%s' has no volume source output: %w", target, resName, err)
+		}
+		source, ok := rawSource.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: source: resource '%s' output 'source' is not a map", target, resName)
+		}
+		volumes[resName] = source
+		volume.Source = resName
+		output[target] = volume
+	}
+	return output, nil
+}
+
+// parseResourceReference returns the resource name from a '${resources.<name>}' placeholder.
+func parseResourceReference(raw string) (string, bool) {
+	if !strings.HasPrefix(raw, "${") || !strings.HasSuffix(raw, "}") {
+		return "", false
+	}
+	parts := framework.SplitRefParts(raw[2 : len(raw)-1])
+	if len(parts) != 2 || parts[0] != "resources" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// convertContainerFiles resolves the content of each file and substitutes any placeholders in it. The returned set
+// contains the targets of files that interpolated resource outputs. Secret resource outputs cannot be written into a
+// file, since the values would then hold them. File modes are given in octal and are rewritten as the decimal number
+// that the Kubernetes API expects.
+func convertContainerFiles(input map[string]scoretypes.ContainerFile, scoreFile *string, sf func(string) (string, error), secretOutputs map[string]map[string]state.SecretKeyRef) (map[string]scoretypes.ContainerFile, map[string]bool, error) {
 	output := make(map[string]scoretypes.ContainerFile, len(input))
+	secretTargets := make(map[string]bool)
 	for target, file := range input {
+		if file.Mode != nil {
+			mode, err := strconv.ParseUint(*file.Mode, 8, 32)
+			if err != nil || mode > 0o777 {
+				return nil, nil, fmt.Errorf("%s: mode: '%s' is not an octal file mode", target, *file.Mode)
+			}
+			decimalMode := strconv.FormatUint(mode, 10)
+			file.Mode = &decimalMode
+		}
+
+		if file.BinaryContent != nil {
+			file.Source = nil
+			file.Content = nil
+			output[target] = file
+			continue
+		}
+
 		var content string
 		if file.Content != nil {
 			content = *file.Content
@@ -129,19 +508,29 @@ func convertContainerFiles(input map[string]scoretypes.ContainerFile, scoreFile
 				sourcePath = filepath.Join(filepath.Dir(*scoreFile), sourcePath)
 			}
 			if rawContent, err := os.ReadFile(sourcePath); err != nil {
-				return nil, fmt.Errorf("%s: source: failed to read file '%s': %w", target, sourcePath, err)
+				return nil, nil, fmt.Errorf("%s: source: failed to read file '%s': %w", target, sourcePath, err)
 			} else {
 				content = string(rawContent)
 			}
 		} else {
-			return nil, fmt.Errorf("%s: missing 'content' or 'source'", target)
+			return nil, nil, fmt.Errorf("%s: missing 'content', 'binaryContent', or 'source'", target)
 		}
 
 		var err error
 		if file.NoExpand == nil || !*file.NoExpand {
-			content, err = framework.SubstituteString(string(content), sf)
+			content, err = framework.SubstituteString(string(content), func(ref string) (string, error) {
+				if parts := framework.SplitRefParts(ref); len(parts) > 0 && parts[0] == "resources" {
+					if len(parts) >= 3 {
+						if secretRef, ok := secretOutputs[parts[1]][parts[2]]; ok {
+							return "", fmt.Errorf("secret output '%s' cannot be written into a file, read it from the Secret '%s' in a variable instead", ref, secretRef.Name)
+						}
+					}
+					secretTargets[target] = true
+				}
+				return sf(ref)
+			})
 			if err != nil {
-				return nil, fmt.Errorf("%s: failed to substitute in content: %w", target, err)
+				return nil, nil, fmt.Errorf("%s: failed to substitute in content: %w", target, err)
 			}
 		}
 		file.Source = nil
@@ -150,5 +539,5 @@ func convertContainerFiles(input map[string]scoretypes.ContainerFile, scoreFile
 		file.NoExpand = &bTrue
 		output[target] = file
 	}
-	return output, nil
+	return output, secretTargets, nil
 }
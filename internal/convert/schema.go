@@ -0,0 +1,28 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	_ "embed"
+)
+
+// ValuesSchemaFileName is the name of the file that Helm validates the chart values against.
+const ValuesSchemaFileName = "values.schema.json"
+
+// ValuesSchema is the JSON schema of the values produced by Workloads and the values fragments of the default
+// provisioners. It must be kept in line with defaultValuesTemplate.
+//
+//go:embed values.schema.json
+var ValuesSchema string
@@ -14,79 +14,216 @@
 
 package convert
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// valuesTemplateFuncs returns the functions available to the values template.
+func valuesTemplateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	funcs["quoteYaml"] = quoteYaml
+	funcs["toYaml"] = toYaml
+	return funcs
+}
+
+// toYaml renders a value as a block of YAML without a trailing newline.
+func toYaml(v interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// quoteYaml renders a value as a double-quoted YAML string. Any JSON string is a valid YAML double-quoted scalar, so
+// this uses the JSON encoder with html escaping disabled.
+func quoteYaml(v interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			v = ""
+		} else {
+			v = rv.Elem().Interface()
+		}
+	}
+	if err := enc.Encode(fmt.Sprint(v)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
 const defaultValuesTemplate = `{{ $workloadName := .WorkloadName }}{{ $service := .Spec.Service }}{{ $resources := .Spec.Resources }}containers:{{ range $containerName, $container := .Spec.Containers }}
   {{ $containerName }}:
     {{- if (gt (len $container.Args) 0) }}
     args:
       {{- range $i, $arg := $container.Args }}
-      - {{ $arg }}
+      - {{ quoteYaml $arg }}
       {{- end }}
     {{- end }}
     {{- if (gt (len $container.Command) 0) }}
     command:
       {{- range $i, $cmd := $container.Command }}
-      - {{ $cmd }}
+      - {{ quoteYaml $cmd }}
       {{- end }}
     {{- end }}
     {{- if (gt (len $container.Variables) 0) }}
     env:
+    {{- range $envVar := index $.SecretEnvVars $containerName }}
+      - name: {{ quoteYaml $envVar.Name }}
+        valueFrom:
+          secretKeyRef:
+            name: {{ quoteYaml $envVar.Ref.Name }}
+            key: {{ quoteYaml $envVar.Ref.Key }}
+    {{- end }}
     {{- range $variableName, $variableValue := $container.Variables }}
-      - name: {{ $variableName }}
-        value: "{{ $variableValue }}"
+      - name: {{ quoteYaml $variableName }}
+        {{- $secretRef := index $.SecretVariables $containerName $variableName }}
+        {{- if ne $secretRef.Name "" }}
+        valueFrom:
+          secretKeyRef:
+            name: {{ quoteYaml $secretRef.Name }}
+            key: {{ quoteYaml $secretRef.Key }}
+        {{- else }}
+        value: {{ quoteYaml $variableValue }}
+        {{- end }}
     {{- end }}
     {{- end }}
+    {{- if (gt (len $container.Files) 0) }}
+    files:
+      {{- range $target, $file := $container.Files }}
+      - target: {{ quoteYaml $target }}
+        {{- if (ne $file.Mode nil) }}
+        mode: {{ $file.Mode }}
+        {{- end }}
+        {{- if (ne $file.BinaryContent nil) }}
+        binaryContent: {{ quoteYaml $file.BinaryContent }}
+        {{- else }}
+        content: {{ quoteYaml $file.Content }}
+        {{- end }}
+        {{- if (index $.SecretFiles $containerName $target) }}
+        secret: true
+        {{- end }}
+      {{- end }}
+    {{- end }}
     image:
-      name: {{ $container.Image }}
-    {{- if (ne $container.LivenessProbe nil) }}
-    livenessProbe:
-      {{- if (ne $container.LivenessProbe.Exec nil) }}
-      exec:
-        command: {{ $container.LivenessProbe.Exec.Command }}
-      {{- else if (ne $container.LivenessProbe.HttpGet nil) }}
-      httpGet:
-        port: {{ $container.LivenessProbe.HttpGet.Port }}
-        {{- if (ne $container.LivenessProbe.HttpGet.Path "") }}
-        path: '{{ $container.LivenessProbe.HttpGet.Path }}'
+      name: {{ quoteYaml $container.Image }}
+    {{- if (gt (len $container.Volumes) 0) }}
+    volumeMounts:
+      {{- range $target, $volume := $container.Volumes }}
+      - name: {{ $volume.Source }}
+        mountPath: {{ quoteYaml $target }}
+        {{- if (ne $volume.Path nil) }}
+        subPath: {{ quoteYaml $volume.Path }}
+        {{- end }}
+        {{- if (ne $volume.ReadOnly nil) }}
+        readOnly: {{ $volume.ReadOnly }}
         {{- end }}
       {{- end }}
     {{- end }}
+    {{- if (ne $container.LivenessProbe nil) }}
+    livenessProbe:
+      {{- template "probe" (dict "Probe" $container.LivenessProbe "Options" (index $.ProbeOptions $containerName "livenessProbe")) }}
+    {{- end }}
     {{- if (ne $container.ReadinessProbe nil) }}
     readinessProbe:
-      {{- if (ne $container.ReadinessProbe.Exec nil) }}
-      exec:
-        command: {{ $container.ReadinessProbe.Exec.Command }}
-      {{- else if (ne $container.ReadinessProbe.HttpGet nil) }}
-      httpGet:
-        port: {{ $container.ReadinessProbe.HttpGet.Port }}
-        {{- if (ne $container.ReadinessProbe.HttpGet.Path "") }}
-        path: '{{ $container.ReadinessProbe.HttpGet.Path }}'
-        {{- end }}
-      {{- end }}
+      {{- template "probe" (dict "Probe" $container.ReadinessProbe "Options" (index $.ProbeOptions $containerName "readinessProbe")) }}
+    {{- end }}
+    {{- with (index $.Extensions.Containers $containerName).SecurityContext }}
+    securityContext:
+      {{- toYaml . | nindent 6 }}
     {{- end }}
     {{- if (ne $container.Resources nil) }}
     resources:
       {{- if (ne $container.Resources.Limits nil) }}
       limits:
         {{- if (ne $container.Resources.Limits.Cpu nil) }}
-        cpu: {{ $container.Resources.Limits.Cpu }}
+        cpu: {{ quoteYaml $container.Resources.Limits.Cpu }}
         {{- end }}
         {{- if (ne $container.Resources.Limits.Memory nil) }}
-        memory: {{ $container.Resources.Limits.Memory }}
+        memory: {{ quoteYaml $container.Resources.Limits.Memory }}
         {{- end }}
       {{- end }}
       {{- if (ne $container.Resources.Requests nil) }}
       requests:
         {{- if (ne $container.Resources.Requests.Cpu nil) }}
-        cpu: {{ $container.Resources.Requests.Cpu }}
+        cpu: {{ quoteYaml $container.Resources.Requests.Cpu }}
         {{- end }}
         {{- if (ne $container.Resources.Requests.Memory nil) }}
-        memory: {{ $container.Resources.Requests.Memory }}
+        memory: {{ quoteYaml $container.Resources.Requests.Memory }}
         {{- end }}
       {{- end }}
     {{- end }}
 {{- end }}
+{{- if (gt (len .Annotations) 0) }}
+annotations:
+  {{- range $key, $value := .Annotations }}
+  {{ quoteYaml $key }}: {{ quoteYaml $value }}
+  {{- end }}
+podAnnotations:
+  {{- range $key, $value := .Annotations }}
+  {{ quoteYaml $key }}: {{ quoteYaml $value }}
+  {{- end }}
+{{- end }}
+{{- if (gt (len .Labels) 0) }}
+labels:
+  {{- range $key, $value := .Labels }}
+  {{ quoteYaml $key }}: {{ quoteYaml $value }}
+  {{- end }}
+{{- end }}
+{{- with .Extensions }}
+{{- if (ne .Replicas nil) }}
+replicas: {{ .Replicas }}
+{{- end }}
+{{- if (ne .ServiceAccountName "") }}
+serviceAccountName: {{ quoteYaml .ServiceAccountName }}
+{{- end }}
+{{- with .NodeSelector }}
+nodeSelector:
+  {{- toYaml . | nindent 2 }}
+{{- end }}
+{{- with .Tolerations }}
+tolerations:
+  {{- toYaml . | nindent 2 }}
+{{- end }}
+{{- with .Affinity }}
+affinity:
+  {{- toYaml . | nindent 2 }}
+{{- end }}
+{{- with .SecurityContext }}
+podSecurityContext:
+  {{- toYaml . | nindent 2 }}
+{{- end }}
+{{- end }}
+{{- if (gt (len .Volumes) 0) }}
+volumes:
+  {{- range $volumeName, $source := .Volumes }}
+  - name: {{ $volumeName }}
+    {{- toYaml $source | nindent 4 }}
+  {{- end }}
+{{- end }}
 {{- if and (ne $service nil) (gt (len $service.Ports) 0) }}
 service:
+  name: {{ quoteYaml $workloadName }}
+  {{- with .Extensions.Service }}
+  {{- if eq .Type "Headless" }}
+  type: "ClusterIP"
+  clusterIP: "None"
+  {{- else if ne .Type "" }}
+  type: {{ quoteYaml .Type }}
+  {{- end }}
+  {{- end }}
   ports:
   {{- range $portName, $port := $service.Ports }}
     - name: {{ $portName }}
@@ -97,6 +234,46 @@ service:
       {{- if ne $port.TargetPort nil }}
       targetPort: {{ $port.TargetPort }}
       {{- end }}
+      {{- with $.Extensions.Service }}
+      {{- $portExtensions := index .Ports $portName }}
+      {{- if ne $portExtensions.NodePort nil }}
+      nodePort: {{ $portExtensions.NodePort }}
+      {{- end }}
+      {{- if ne $portExtensions.AppProtocol "" }}
+      appProtocol: {{ quoteYaml $portExtensions.AppProtocol }}
+      {{- end }}
+      {{- end }}
   {{- end }}
 {{- end }}
+{{- define "probe" }}
+      {{- if (ne .Probe.Exec nil) }}
+      exec:
+        command:
+          {{- range $i, $arg := .Probe.Exec.Command }}
+          - {{ quoteYaml $arg }}
+          {{- end }}
+      {{- else if (ne .Probe.HttpGet nil) }}
+      httpGet:
+        port: {{ .Probe.HttpGet.Port }}
+        {{- if (ne .Probe.HttpGet.Path "") }}
+        path: {{ quoteYaml .Probe.HttpGet.Path }}
+        {{- end }}
+        {{- if (ne .Probe.HttpGet.Host nil) }}
+        host: {{ quoteYaml .Probe.HttpGet.Host }}
+        {{- end }}
+        {{- if (ne .Probe.HttpGet.Scheme nil) }}
+        scheme: {{ quoteYaml .Probe.HttpGet.Scheme }}
+        {{- end }}
+        {{- if (gt (len .Probe.HttpGet.HttpHeaders) 0) }}
+        httpHeaders:
+          {{- range $i, $header := .Probe.HttpGet.HttpHeaders }}
+          - name: {{ quoteYaml $header.Name }}
+            value: {{ quoteYaml $header.Value }}
+          {{- end }}
+        {{- end }}
+      {{- end }}
+      {{- range $option, $value := .Options }}
+      {{ $option }}: {{ $value }}
+      {{- end }}
+{{- end }}
 `
\ No newline at end of file
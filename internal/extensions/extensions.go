@@ -0,0 +1,116 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceTypes are the supported Kubernetes Service types. A Headless service is a ClusterIP service without a cluster IP.
+var ServiceTypes = []string{"ClusterIP", "NodePort", "LoadBalancer", "Headless"}
+
+// Workload holds the Kubernetes-specific settings of a workload that have no equivalent in the Score specification.
+// These are merged into the values of the workload next to the containers and service.
+type Workload struct {
+	Replicas           *int                   `yaml:"replicas,omitempty"`
+	ServiceAccountName string                 `yaml:"serviceAccountName,omitempty"`
+	NodeSelector       map[string]string      `yaml:"nodeSelector,omitempty"`
+	Tolerations        []interface{}          `yaml:"tolerations,omitempty"`
+	Affinity           map[string]interface{} `yaml:"affinity,omitempty"`
+	// SecurityContext is the pod security context.
+	SecurityContext map[string]interface{} `yaml:"securityContext,omitempty"`
+	Service         *Service               `yaml:"service,omitempty"`
+	Containers      map[string]Container   `yaml:"containers,omitempty"`
+}
+
+// Service holds the settings of the workload Service.
+type Service struct {
+	Type string `yaml:"type,omitempty"`
+	// Ports holds the settings of the ports of the workload service keyed by port name.
+	Ports map[string]ServicePort `yaml:"ports,omitempty"`
+}
+
+// ServicePort holds the settings of a port of the workload Service.
+type ServicePort struct {
+	NodePort    *int   `yaml:"nodePort,omitempty"`
+	AppProtocol string `yaml:"appProtocol,omitempty"`
+}
+
+// Container holds the settings of a container of the workload.
+type Container struct {
+	SecurityContext map[string]interface{} `yaml:"securityContext,omitempty"`
+}
+
+// Validate checks the settings that can be checked without the workload spec.
+func (w *Workload) Validate() error {
+	if w.Replicas != nil && *w.Replicas < 0 {
+		return fmt.Errorf("replicas: must not be negative")
+	}
+	if w.Service == nil {
+		return nil
+	}
+	if w.Service.Type != "" && !slices.Contains(ServiceTypes, w.Service.Type) {
+		return fmt.Errorf("service: type: expected one of %v", ServiceTypes)
+	}
+	for portName, port := range w.Service.Ports {
+		if port.NodePort == nil {
+			continue
+		}
+		if *port.NodePort < 1 || *port.NodePort > 65535 {
+			return fmt.Errorf("service: ports: %s: nodePort: must be between 1 and 65535", portName)
+		}
+		if w.Service.Type != "NodePort" && w.Service.Type != "LoadBalancer" {
+			return fmt.Errorf("service: ports: %s: nodePort: requires a service type of NodePort or LoadBalancer", portName)
+		}
+	}
+	return nil
+}
+
+// ParseFile decodes an extensions file. This holds the extensions of each workload keyed by workload name:
+//
+//	my-workload:
+//	  replicas: 3
+//	  service:
+//	    type: LoadBalancer
+func ParseFile(raw []byte) (map[string]Workload, error) {
+	out := make(map[string]Workload)
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&out); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+	for name, workload := range out {
+		if err := workload.Validate(); err != nil {
+			return nil, fmt.Errorf("workload '%s': %w", name, err)
+		}
+	}
+	return out, nil
+}
+
+// LoadFile reads and decodes an extensions file.
+func LoadFile(path string) (map[string]Workload, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+	return ParseFile(raw)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	out, err := ParseFile([]byte(`
+example:
+  replicas: 3
+  serviceAccountName: example
+  nodeSelector:
+    kubernetes.io/os: linux
+  securityContext:
+    runAsNonRoot: true
+  service:
+    type: LoadBalancer
+  containers:
+    main:
+      securityContext:
+        readOnlyRootFilesystem: true
+`))
+	require.NoError(t, err)
+	replicas := 3
+	assert.Equal(t, map[string]Workload{
+		"example": {
+			Replicas:           &replicas,
+			ServiceAccountName: "example",
+			NodeSelector:       map[string]string{"kubernetes.io/os": "linux"},
+			SecurityContext:    map[string]interface{}{"runAsNonRoot": true},
+			Service:            &Service{Type: "LoadBalancer"},
+			Containers: map[string]Container{
+				"main": {SecurityContext: map[string]interface{}{"readOnlyRootFilesystem": true}},
+			},
+		},
+	}, out)
+}
+
+func TestParseFile_empty(t *testing.T) {
+	out, err := ParseFile([]byte(""))
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestParseFile_invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		raw  string
+		err  string
+	}{
+		{
+			name: "unknown field",
+			raw:  "example:\n  replica: 3\n",
+			err:  "failed to decode: yaml: unmarshal errors:\n  line 2: field replica not found in type extensions.Workload",
+		},
+		{
+			name: "negative replicas",
+			raw:  "example:\n  replicas: -1\n",
+			err:  "workload 'example': replicas: must not be negative",
+		},
+		{
+			name: "unknown service type",
+			raw:  "example:\n  service:\n    type: ExternalName\n",
+			err:  "workload 'example': service: type: expected one of [ClusterIP NodePort LoadBalancer Headless]",
+		},
+		{
+			name: "node port out of range",
+			raw:  "example:\n  service:\n    type: NodePort\n    ports:\n      web:\n        nodePort: 70000\n",
+			err:  "workload 'example': service: ports: web: nodePort: must be between 1 and 65535",
+		},
+		{
+			name: "node port on cluster ip",
+			raw:  "example:\n  service:\n    ports:\n      web:\n        nodePort: 30080\n",
+			err:  "workload 'example': service: ports: web: nodePort: requires a service type of NodePort or LoadBalancer",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFile([]byte(tc.raw))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
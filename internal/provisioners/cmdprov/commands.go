@@ -0,0 +1,149 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdprov
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/score-spec/score-go/framework"
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+)
+
+// Provisioner is the decoded command provisioner.
+// A command provisioner provisions a resource by running an external binary. The provisioner input is written to the
+// standard input of the process as JSON, and the provisioner output is read back from its standard output as JSON.
+type Provisioner struct {
+	ProvisionerUri string   `yaml:"uri"`
+	ResType        string   `yaml:"type"`
+	ResClass       *string  `yaml:"class,omitempty"`
+	ResId          *string  `yaml:"id,omitempty"`
+	Args           []string `yaml:"args,omitempty"`
+}
+
+// Parse decodes a command provisioner from its raw yaml form.
+func Parse(raw map[string]interface{}) (*Provisioner, error) {
+	p := new(Provisioner)
+	intermediate, _ := yaml.Marshal(raw)
+	dec := yaml.NewDecoder(bytes.NewReader(intermediate))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	if p.ProvisionerUri == "" {
+		return nil, fmt.Errorf("uri not set")
+	} else if p.ResType == "" {
+		return nil, fmt.Errorf("type not set")
+	} else if _, err := decodeBinary(p.ProvisionerUri); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Provisioner) Uri() string {
+	return p.ProvisionerUri
+}
+
+func (p *Provisioner) Match(resUid framework.ResourceUid) bool {
+	if resUid.Type() != p.ResType {
+		return false
+	} else if p.ResClass != nil && resUid.Class() != *p.ResClass {
+		return false
+	} else if p.ResId != nil && resUid.Id() != *p.ResId {
+		return false
+	}
+	return true
+}
+
+// decodeBinary converts the provisioner uri into the path of the binary to execute. The host part of the uri is
+// either '.' for a path relative to the working directory, '~' for a path relative to the home directory, or the name
+// of a binary to look up on the $PATH.
+func decodeBinary(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid uri '%s': %w", uri, err)
+	} else if u.Scheme != "cmd" {
+		return "", fmt.Errorf("invalid uri '%s': expected scheme 'cmd'", uri)
+	}
+	switch u.Host {
+	case "":
+		return "", fmt.Errorf("invalid uri '%s': a host of '.', '~', or a binary name is required", uri)
+	case ".":
+		abs, err := filepath.Abs(filepath.FromSlash(strings.TrimPrefix(u.Path, "/")))
+		if err != nil {
+			return "", fmt.Errorf("invalid uri '%s': %w", uri, err)
+		}
+		return abs, nil
+	case "~":
+		hd, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("invalid uri '%s': failed to determine home directory: %w", uri, err)
+		}
+		return filepath.Join(hd, filepath.FromSlash(u.Path)), nil
+	default:
+		if u.Path != "" {
+			return "", fmt.Errorf("invalid uri '%s': a binary name must not have a path", uri)
+		}
+		return u.Host, nil
+	}
+}
+
+func (p *Provisioner) Provision(ctx context.Context, input *provisioners.Input) (*provisioners.ProvisionOutput, error) {
+	bin, err := decodeBinary(p.ProvisionerUri)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsRune(bin, filepath.Separator) {
+		if bin, err = exec.LookPath(bin); err != nil {
+			return nil, fmt.Errorf("failed to find binary: %w", err)
+		}
+	}
+
+	rawInput, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode input: %w", err)
+	}
+
+	outputBuffer := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, bin, p.Args...)
+	cmd.Stdin = bytes.NewReader(rawInput)
+	cmd.Stdout = outputBuffer
+	cmd.Stderr = os.Stderr
+	slog.Debug(fmt.Sprintf("Executing '%s %v' for command provisioner", bin, p.Args))
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to execute cmd provisioner: %w", err)
+	}
+
+	var output provisioners.ProvisionOutput
+	dec := json.NewDecoder(bytes.NewReader(outputBuffer.Bytes()))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&output); err != nil {
+		slog.Debug("cmd provisioner output was not valid json", "raw", outputBuffer.String())
+		return nil, fmt.Errorf("failed to decode output from cmd provisioner: %w", err)
+	}
+	return &output, nil
+}
+
+var _ provisioners.Provisioner = (*Provisioner)(nil)
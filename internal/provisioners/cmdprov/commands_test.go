@@ -0,0 +1,103 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdprov
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+)
+
+func TestDecodeBinary(t *testing.T) {
+	wd, _ := os.Getwd()
+	hd, _ := os.UserHomeDir()
+	for _, tc := range []struct {
+		uri    string
+		expect string
+		err    string
+	}{
+		{uri: "cmd://bash", expect: "bash"},
+		{uri: "cmd://./scripts/thing.sh", expect: filepath.Join(wd, "scripts", "thing.sh")},
+		{uri: "cmd://~/bin/thing", expect: filepath.Join(hd, "bin", "thing")},
+		{uri: "cmd:///thing", err: "invalid uri 'cmd:///thing': a host of '.', '~', or a binary name is required"},
+		{uri: "cmd://bash/thing", err: "invalid uri 'cmd://bash/thing': a binary name must not have a path"},
+		{uri: "template://bash", err: "invalid uri 'template://bash': expected scheme 'cmd'"},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			out, err := decodeBinary(tc.uri)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expect, out)
+			}
+		})
+	}
+}
+
+func TestProvision(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+	td := t.TempDir()
+	script := filepath.Join(td, "provision.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"resource_type":"thing"'*) ;;
+  *) echo "unexpected input: $input" >&2; exit 1 ;;
+esac
+echo '{"resource_state": {"k": "v"}, "resource_outputs": {"arg": "'"$1"'"}, "shared_state": {"s": 1}, "values": {"x": true}}'
+`), 0755))
+
+	p, err := Parse(map[string]interface{}{
+		"uri":  "cmd://sh",
+		"type": "thing",
+		"args": []interface{}{script, "hello"},
+	})
+	require.NoError(t, err)
+	out, err := p.Provision(context.Background(), &provisioners.Input{ResourceType: "thing"})
+	require.NoError(t, err)
+	assert.Equal(t, &provisioners.ProvisionOutput{
+		ResourceState:   map[string]interface{}{"k": "v"},
+		ResourceOutputs: map[string]interface{}{"arg": "hello"},
+		SharedState:     map[string]interface{}{"s": float64(1)},
+		Values:          map[string]interface{}{"x": true},
+	}, out)
+
+	_, err = p.Provision(context.Background(), &provisioners.Input{ResourceType: "other"})
+	assert.EqualError(t, err, "failed to execute cmd provisioner: exit status 1")
+}
+
+func TestProvision_bad_output(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+	p, err := Parse(map[string]interface{}{
+		"uri":  "cmd://sh",
+		"type": "thing",
+		"args": []interface{}{"-c", `echo '{"unknown": 1}'`},
+	})
+	require.NoError(t, err)
+	_, err = p.Provision(context.Background(), &provisioners.Input{})
+	assert.EqualError(t, err, "failed to decode output from cmd provisioner: json: unknown field \"unknown\"")
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package default_provisioners
+
+import (
+	_ "embed"
+)
+
+// DefaultProvisionersFileName is the name of the file that init writes the default provisioners to. The zz- prefix
+// ensures that provisioners in any other file take precedence.
+const DefaultProvisionersFileName = "zz-default.provisioners.yaml"
+
+//go:embed zz-default.provisioners.yaml
+var DefaultProvisioners string
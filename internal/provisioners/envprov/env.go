@@ -0,0 +1,85 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envprov
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/score-spec/score-go/framework"
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+)
+
+// Provisioner is the decoded environment provisioner.
+// An environment provisioner resolves each output key from the environment variables of the score-helm process at
+// generate time. The outputs are looked up lazily and are never persisted to the state file.
+type Provisioner struct {
+	ProvisionerUri string  `yaml:"uri"`
+	ResType        string  `yaml:"type"`
+	ResClass       *string `yaml:"class,omitempty"`
+	ResId          *string `yaml:"id,omitempty"`
+}
+
+// Parse decodes an environment provisioner from its raw yaml form.
+func Parse(raw map[string]interface{}) (*Provisioner, error) {
+	p := new(Provisioner)
+	intermediate, _ := yaml.Marshal(raw)
+	dec := yaml.NewDecoder(bytes.NewReader(intermediate))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	if p.ProvisionerUri == "" {
+		return nil, fmt.Errorf("uri not set")
+	} else if p.ResType == "" {
+		return nil, fmt.Errorf("type not set")
+	}
+	return p, nil
+}
+
+func (p *Provisioner) Uri() string {
+	return p.ProvisionerUri
+}
+
+func (p *Provisioner) Match(resUid framework.ResourceUid) bool {
+	if resUid.Type() != p.ResType {
+		return false
+	} else if p.ResClass != nil && resUid.Class() != *p.ResClass {
+		return false
+	} else if p.ResId != nil && resUid.Id() != *p.ResId {
+		return false
+	}
+	return true
+}
+
+func (p *Provisioner) Provision(ctx context.Context, input *provisioners.Input) (*provisioners.ProvisionOutput, error) {
+	return &provisioners.ProvisionOutput{
+		OutputLookupFunc: func(keys ...string) (interface{}, error) {
+			if len(keys) != 1 {
+				return nil, fmt.Errorf("environment resource only supports a single lookup key")
+			}
+			if v, ok := os.LookupEnv(keys[0]); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("environment variable '%s' is not set", keys[0])
+		},
+	}, nil
+}
+
+var _ provisioners.Provisioner = (*Provisioner)(nil)
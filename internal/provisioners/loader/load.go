@@ -0,0 +1,105 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+	"github.com/score-spec/score-helm/internal/provisioners/cmdprov"
+	"github.com/score-spec/score-helm/internal/provisioners/envprov"
+	"github.com/score-spec/score-helm/internal/provisioners/templateprov"
+)
+
+const DefaultSuffix = ".provisioners.yaml"
+
+// LoadProvisioners decodes a list of provisioners from a raw yaml document.
+func LoadProvisioners(raw []byte) ([]provisioners.Provisioner, error) {
+	var intermediate []map[string]interface{}
+	if err := yaml.Unmarshal(raw, &intermediate); err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+	out := make([]provisioners.Provisioner, 0, len(intermediate))
+	for i, m := range intermediate {
+		uri, _ := m["uri"].(string)
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("%d: invalid uri '%s'", i, uri)
+		}
+		switch u.Scheme {
+		case "template":
+			if p, err := templateprov.Parse(m); err != nil {
+				return nil, fmt.Errorf("%d: %s: failed to parse: %w", i, uri, err)
+			} else {
+				slog.Debug(fmt.Sprintf("Loaded provisioner %s", p.Uri()))
+				out = append(out, p)
+			}
+		case "cmd":
+			if p, err := cmdprov.Parse(m); err != nil {
+				return nil, fmt.Errorf("%d: %s: failed to parse: %w", i, uri, err)
+			} else {
+				slog.Debug(fmt.Sprintf("Loaded provisioner %s", p.Uri()))
+				out = append(out, p)
+			}
+		case "env":
+			if p, err := envprov.Parse(m); err != nil {
+				return nil, fmt.Errorf("%d: %s: failed to parse: %w", i, uri, err)
+			} else {
+				slog.Debug(fmt.Sprintf("Loaded provisioner %s", p.Uri()))
+				out = append(out, p)
+			}
+		default:
+			return nil, fmt.Errorf("%d: unsupported provisioner type '%s'", i, u.Scheme)
+		}
+	}
+	return out, nil
+}
+
+// LoadProvisionersFromDirectory loads all the provisioners from files with the given suffix in the directory. Files
+// are loaded in lexicographic order, so provisioners in earlier files take precedence over later ones.
+func LoadProvisionersFromDirectory(path string, suffix string) ([]provisioners.Provisioner, error) {
+	items, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read provisioners directory: %w", err)
+	}
+	out := make([]provisioners.Provisioner, 0)
+	for _, item := range items {
+		if item.IsDir() || !strings.HasSuffix(item.Name(), suffix) {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(path, item.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", item.Name(), err)
+		}
+		p, err := LoadProvisioners(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load '%s': %w", item.Name(), err)
+		}
+		slog.Info(fmt.Sprintf("Loaded %d provisioners from %s", len(p), item.Name()))
+		out = append(out, p...)
+	}
+	return out, nil
+}
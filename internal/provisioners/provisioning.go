@@ -15,15 +15,156 @@
 package provisioners
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"maps"
 
 	"github.com/score-spec/score-go/framework"
+	scoretypes "github.com/score-spec/score-go/types"
 
 	"github.com/score-spec/score-helm/internal/state"
 )
 
-func ProvisionResources(currentState *state.State) (*state.State, error) {
+// Input is the set of things passed to the provisioner implementation. It provides context, previous state, and shared
+// state used by all resources.
+type Input struct {
+	// -- aspects from the resource declaration --
+
+	ResourceUid      string                 `json:"resource_uid"`
+	ResourceGuid     string                 `json:"resource_guid"`
+	ResourceType     string                 `json:"resource_type"`
+	ResourceClass    string                 `json:"resource_class"`
+	ResourceId       string                 `json:"resource_id"`
+	ResourceParams   map[string]interface{} `json:"resource_params"`
+	ResourceMetadata map[string]interface{} `json:"resource_metadata"`
+
+	// -- aspects of the workloads --
+
+	// SourceWorkload is the name of the workload that first defined this resource or carries the params definition.
+	SourceWorkload string `json:"source_workload"`
+	// Consumers are the names of the workloads that use this resource, in sorted order.
+	Consumers []string `json:"consumers"`
+	// WorkloadServices is a map from workload name to the network service it exposes.
+	WorkloadServices map[string]NetworkService `json:"workload_services"`
+
+	// -- current state --
+
+	ResourceState map[string]interface{} `json:"resource_state"`
+	SharedState   map[string]interface{} `json:"shared_state"`
+}
+
+// NetworkService describes the Service that the chart creates for a workload.
+type NetworkService struct {
+	ServiceName string                 `json:"service_name"`
+	Ports       map[string]ServicePort `json:"ports"`
+}
+
+type ServicePort struct {
+	// Name is the name of the port from the workload specification
+	Name string `json:"name"`
+	// Port is the numeric port intended to be published
+	Port int `json:"port"`
+	// TargetPort is the port on the workload that hosts the actual traffic
+	TargetPort int `json:"target_port"`
+	// Protocol is TCP or UDP.
+	Protocol string `json:"protocol"`
+}
+
+// ProvisionOutput is the output returned from a provisioner implementation.
+type ProvisionOutput struct {
+	ProvisionerUri  string                 `json:"-"`
+	ResourceState   map[string]interface{} `json:"resource_state"`
+	ResourceOutputs map[string]interface{} `json:"resource_outputs"`
+	SharedState     map[string]interface{} `json:"shared_state"`
+	// Values is a fragment that is merged into the values output of any workload that depends on this resource.
+	Values map[string]interface{} `json:"values"`
+	// Dependencies are charts that are added to the dependencies of a chart generated for any workload that depends
+	// on this resource. The values of a dependency can be set through Values under its name or alias.
+	Dependencies []state.ChartDependency `json:"dependencies"`
+	// Secrets marks outputs as secret by naming the Kubernetes Secret key that holds each of them, keyed by output
+	// name. The provisioner is responsible for creating the Secret, for example through Values.
+	Secrets map[string]state.SecretKeyRef `json:"secrets"`
+	// OutputLookupFunc is an optional function used by in-process provisioners to resolve outputs lazily. It is not
+	// persisted to the state file.
+	OutputLookupFunc framework.OutputLookupFunc `json:"-"`
+}
+
+// Provisioner is the interface implemented by all resource provisioners.
+type Provisioner interface {
+	Uri() string
+	Match(resUid framework.ResourceUid) bool
+	Provision(ctx context.Context, input *Input) (*ProvisionOutput, error)
+}
+
+// ApplyToState applies the output of a provisioner to the given resource in the state. This does not modify the
+// input state and returns a shallow copy with the changes applied.
+func (po *ProvisionOutput) ApplyToState(currentState *state.State, resUid framework.ResourceUid) (*state.State, error) {
+	if currentState == nil {
+		return nil, fmt.Errorf("state is nil")
+	}
+	out := *currentState
+	out.Resources = maps.Clone(currentState.Resources)
+
+	existing, ok := out.Resources[resUid]
+	if !ok {
+		return nil, fmt.Errorf("failed to apply to state - unknown res uid")
+	}
+
+	// Update the provisioner string
+	existing.ProvisionerUri = po.ProvisionerUri
+
+	// State must ALWAYS be updated. If we don't get state back, we assume it's now empty.
+	if po.ResourceState != nil {
+		existing.State = po.ResourceState
+	} else {
+		existing.State = make(map[string]interface{})
+	}
+
+	// Same with outputs, it must ALWAYS be updated.
+	if po.ResourceOutputs != nil {
+		existing.Outputs = po.ResourceOutputs
+	} else {
+		existing.Outputs = make(map[string]interface{})
+	}
+
+	existing.OutputLookupFunc = po.OutputLookupFunc
+	existing.Extras.Values = po.Values
+	for i, dependency := range po.Dependencies {
+		if dependency.Name == "" {
+			return nil, fmt.Errorf("dependency %d: name is empty", i)
+		}
+	}
+	existing.Extras.Dependencies = po.Dependencies
+	for outputName, ref := range po.Secrets {
+		if ref.Name == "" || ref.Key == "" {
+			return nil, fmt.Errorf("secret '%s': expected a name and key", outputName)
+		}
+	}
+	existing.Extras.Secrets = po.Secrets
+
+	// Shared state keys are patched individually, a nil value removes the key.
+	if len(po.SharedState) > 0 {
+		out.SharedState = maps.Clone(currentState.SharedState)
+		if out.SharedState == nil {
+			out.SharedState = make(map[string]interface{})
+		}
+		for k, v := range po.SharedState {
+			if v == nil {
+				delete(out.SharedState, k)
+			} else {
+				out.SharedState[k] = v
+			}
+		}
+	}
+
+	out.Resources[resUid] = existing
+	return &out, nil
+}
+
+// ProvisionResources runs each resource in the state through the first provisioner that matches it. Resources are
+// provisioned in dependency order so that params may refer to the outputs of other resources.
+func ProvisionResources(ctx context.Context, currentState *state.State, provisioners []Provisioner) (*state.State, error) {
 	out := currentState
 
 	// provision in sorted order
@@ -32,6 +173,8 @@ func ProvisionResources(currentState *state.State) (*state.State, error) {
 		return nil, fmt.Errorf("failed to determine sort order for provisioning: %w", err)
 	}
 
+	workloadServices := buildWorkloadServices(currentState)
+
 	out.Resources = maps.Clone(out.Resources)
 	for _, resUid := range orderedResources {
 		resState := out.Resources[resUid]
@@ -50,14 +193,73 @@ func ProvisionResources(currentState *state.State) (*state.State, error) {
 			params = rawParams.(map[string]interface{})
 		}
 		resState.Params = params
+		out.Resources[resUid] = resState
 
-		// ==========================================================================================
-		// TODO: HERE IS WHERE YOU WOULD USE THE RESOURCE TYPE, CLASS, ID, AND PARAMS TO PROVISION IT
-		// ==========================================================================================
+		var provisioner Provisioner
+		for _, p := range provisioners {
+			if p.Match(resUid) {
+				provisioner = p
+				break
+			}
+		}
+		if provisioner == nil {
+			slog.Warn(fmt.Sprintf("No provisioner matched resource '%s', it will have no outputs", resUid))
+			resState.ProvisionerUri = ""
+			resState.Outputs = map[string]interface{}{}
+			resState.Extras.Values = nil
+			resState.Extras.Dependencies = nil
+			resState.Extras.Secrets = nil
+			out.Resources[resUid] = resState
+			continue
+		}
 
-		resState.Outputs = map[string]interface{}{}
-		out.Resources[resUid] = resState
+		output, err := provisioner.Provision(ctx, &Input{
+			ResourceUid:      string(resUid),
+			ResourceGuid:     resState.Guid,
+			ResourceType:     resState.Type,
+			ResourceClass:    resState.Class,
+			ResourceId:       resState.Id,
+			ResourceParams:   params,
+			ResourceMetadata: resState.Metadata,
+			SourceWorkload:   resState.SourceWorkload,
+			Consumers:        resState.Extras.Consumers,
+			WorkloadServices: workloadServices,
+			ResourceState:    resState.State,
+			SharedState:      out.SharedState,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("resource '%s': failed to provision: %w", resUid, err)
+		}
+
+		output.ProvisionerUri = provisioner.Uri()
+		if out, err = output.ApplyToState(out, resUid); err != nil {
+			return nil, fmt.Errorf("resource '%s': failed to apply outputs: %w", resUid, err)
+		}
+		slog.Info(fmt.Sprintf("Provisioned resource '%s' with '%s'", resUid, provisioner.Uri()))
 	}
 
 	return out, nil
 }
+
+// buildWorkloadServices returns the network service of each workload that declares service ports. The values name the
+// Service of each workload after the workload in service.name, which the chart uses in both values layouts.
+func buildWorkloadServices(currentState *state.State) map[string]NetworkService {
+	out := make(map[string]NetworkService, len(currentState.Workloads))
+	for workloadName, workload := range currentState.Workloads {
+		ns := NetworkService{ServiceName: workloadName, Ports: make(map[string]ServicePort)}
+		if workload.Spec.Service != nil {
+			for portName, port := range workload.Spec.Service.Ports {
+				sp := ServicePort{Name: portName, Port: port.Port, TargetPort: port.Port, Protocol: string(scoretypes.ServicePortProtocolTCP)}
+				if port.TargetPort != nil {
+					sp.TargetPort = *port.TargetPort
+				}
+				if port.Protocol != nil {
+					sp.Protocol = string(*port.Protocol)
+				}
+				ns.Ports[portName] = sp
+			}
+		}
+		out[workloadName] = ns
+	}
+	return out
+}
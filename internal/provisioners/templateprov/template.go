@@ -0,0 +1,198 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templateprov
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/score-spec/score-go/framework"
+	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+)
+
+// Provisioner is the decoded template provisioner.
+// A template provisioner provisions a resource by evaluating a series of Go text/templates that have access to some
+// input parameters, previous state, and utility functions. Each template is expected to render a YAML object.
+type Provisioner struct {
+	ProvisionerUri string  `yaml:"uri"`
+	ResType        string  `yaml:"type"`
+	ResClass       *string `yaml:"class,omitempty"`
+	ResId          *string `yaml:"id,omitempty"`
+
+	// The InitTemplate is always evaluated first, it is used as temporary or working set data that may be needed in the
+	// later templates. It has access to the resource inputs and previous state.
+	InitTemplate string `yaml:"init,omitempty"`
+	// StateTemplate generates the new state of the resource based on the init and previous state.
+	StateTemplate string `yaml:"state,omitempty"`
+	// SharedStateTemplate generates modifications to the shared state, based on the init and current state.
+	SharedStateTemplate string `yaml:"shared,omitempty"`
+	// OutputsTemplate generates the outputs of the resource, based on the init and current state.
+	OutputsTemplate string `yaml:"outputs,omitempty"`
+	// ValuesTemplate generates a fragment that is merged into the values output, based on the init and current state.
+	ValuesTemplate string `yaml:"values,omitempty"`
+	// DependenciesTemplate generates a list of charts that are added to the dependencies of a generated chart, based
+	// on the init and current state.
+	DependenciesTemplate string `yaml:"dependencies,omitempty"`
+	// SecretsTemplate generates the Kubernetes Secret name and key of each output that is secret, keyed by output
+	// name, based on the init and current state.
+	SecretsTemplate string `yaml:"secrets,omitempty"`
+}
+
+// Parse decodes a template provisioner from its raw yaml form.
+func Parse(raw map[string]interface{}) (*Provisioner, error) {
+	p := new(Provisioner)
+	intermediate, _ := yaml.Marshal(raw)
+	dec := yaml.NewDecoder(bytes.NewReader(intermediate))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	if p.ProvisionerUri == "" {
+		return nil, fmt.Errorf("uri not set")
+	} else if p.ResType == "" {
+		return nil, fmt.Errorf("type not set")
+	}
+	return p, nil
+}
+
+func (p *Provisioner) Uri() string {
+	return p.ProvisionerUri
+}
+
+func (p *Provisioner) Match(resUid framework.ResourceUid) bool {
+	if resUid.Type() != p.ResType {
+		return false
+	} else if p.ResClass != nil && resUid.Class() != *p.ResClass {
+		return false
+	} else if p.ResId != nil && resUid.Id() != *p.ResId {
+		return false
+	}
+	return true
+}
+
+func renderTemplateAndDecode(raw string, data interface{}, out interface{}) error {
+	if raw == "" {
+		return nil
+	}
+	prepared, err := template.New("").Funcs(sprig.FuncMap()).Parse(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := prepared.Execute(buff, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	if strings.TrimSpace(buff.String()) == "" {
+		return nil
+	}
+	if err := yaml.Unmarshal(buff.Bytes(), out); err != nil {
+		slog.Debug("template output was not valid yaml", "raw", buff.String())
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+	return nil
+}
+
+// Data is the structure sent to each template during rendering.
+type Data struct {
+	// Guid is a random uuid generated the first time this resource is added to the project.
+	Guid string
+	Uid  string
+	Type string
+	// Class is the resource class, or "default" if not set.
+	Class string
+	Id    string
+
+	Params   map[string]interface{}
+	Metadata map[string]interface{}
+
+	Init   map[string]interface{}
+	State  map[string]interface{}
+	Shared map[string]interface{}
+
+	SourceWorkload   string
+	Consumers        []string
+	WorkloadServices map[string]provisioners.NetworkService
+}
+
+func (p *Provisioner) Provision(ctx context.Context, input *provisioners.Input) (*provisioners.ProvisionOutput, error) {
+	out := &provisioners.ProvisionOutput{}
+
+	data := Data{
+		Guid:             input.ResourceGuid,
+		Uid:              input.ResourceUid,
+		Type:             input.ResourceType,
+		Class:            input.ResourceClass,
+		Id:               input.ResourceId,
+		Params:           input.ResourceParams,
+		Metadata:         input.ResourceMetadata,
+		State:            input.ResourceState,
+		Shared:           input.SharedState,
+		SourceWorkload:   input.SourceWorkload,
+		Consumers:        input.Consumers,
+		WorkloadServices: input.WorkloadServices,
+	}
+
+	init := make(map[string]interface{})
+	if err := renderTemplateAndDecode(p.InitTemplate, &data, &init); err != nil {
+		return nil, fmt.Errorf("init template failed: %w", err)
+	}
+	data.Init = init
+
+	out.ResourceState = make(map[string]interface{})
+	if err := renderTemplateAndDecode(p.StateTemplate, &data, &out.ResourceState); err != nil {
+		return nil, fmt.Errorf("state template failed: %w", err)
+	}
+	data.State = out.ResourceState
+
+	out.SharedState = make(map[string]interface{})
+	if err := renderTemplateAndDecode(p.SharedStateTemplate, &data, &out.SharedState); err != nil {
+		return nil, fmt.Errorf("shared template failed: %w", err)
+	}
+	data.Shared = make(map[string]interface{}, len(input.SharedState)+len(out.SharedState))
+	for k, v := range input.SharedState {
+		data.Shared[k] = v
+	}
+	for k, v := range out.SharedState {
+		data.Shared[k] = v
+	}
+
+	out.ResourceOutputs = make(map[string]interface{})
+	if err := renderTemplateAndDecode(p.OutputsTemplate, &data, &out.ResourceOutputs); err != nil {
+		return nil, fmt.Errorf("outputs template failed: %w", err)
+	}
+
+	if err := renderTemplateAndDecode(p.ValuesTemplate, &data, &out.Values); err != nil {
+		return nil, fmt.Errorf("values template failed: %w", err)
+	}
+
+	if err := renderTemplateAndDecode(p.DependenciesTemplate, &data, &out.Dependencies); err != nil {
+		return nil, fmt.Errorf("dependencies template failed: %w", err)
+	}
+
+	if err := renderTemplateAndDecode(p.SecretsTemplate, &data, &out.Secrets); err != nil {
+		return nil, fmt.Errorf("secrets template failed: %w", err)
+	}
+
+	return out, nil
+}
+
+var _ provisioners.Provisioner = (*Provisioner)(nil)
@@ -0,0 +1,107 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templateprov
+
+import (
+	"context"
+	"testing"
+
+	"github.com/score-spec/score-go/framework"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/score-spec/score-helm/internal/provisioners"
+	"github.com/score-spec/score-helm/internal/state"
+)
+
+func TestParse_missing_fields(t *testing.T) {
+	_, err := Parse(map[string]interface{}{"type": "thing"})
+	assert.EqualError(t, err, "uri not set")
+	_, err = Parse(map[string]interface{}{"uri": "template://example"})
+	assert.EqualError(t, err, "type not set")
+	_, err = Parse(map[string]interface{}{"uri": "template://example", "type": "thing", "unknown": "x"})
+	assert.ErrorContains(t, err, "field unknown not found")
+}
+
+func TestMatch(t *testing.T) {
+	class, id := "large", "shared"
+	p := &Provisioner{ProvisionerUri: "template://example", ResType: "thing", ResClass: &class, ResId: &id}
+	assert.True(t, p.Match(framework.NewResourceUid("w", "r", "thing", &class, &id)))
+	assert.False(t, p.Match(framework.NewResourceUid("w", "r", "thing", nil, &id)))
+	assert.False(t, p.Match(framework.NewResourceUid("w", "r", "thing", &class, nil)))
+	assert.False(t, p.Match(framework.NewResourceUid("w", "r", "other", &class, &id)))
+	p = &Provisioner{ProvisionerUri: "template://example", ResType: "thing"}
+	assert.True(t, p.Match(framework.NewResourceUid("w", "r", "thing", &class, nil)))
+}
+
+func TestProvision(t *testing.T) {
+	p, err := Parse(map[string]interface{}{
+		"uri":  "template://example",
+		"type": "thing",
+		"init": `host: {{ .Id | replace "." "-" }}`,
+		"state": `
+counter: {{ add (default 0 .State.counter) 1 }}`,
+		"shared": `
+seen: {{ .Guid }}`,
+		"outputs": `
+host: {{ .Init.host }}
+port: {{ .Params.port }}
+counter: {{ .State.counter }}
+seen: {{ .Shared.seen }}`,
+		"values": `
+extra:
+  host: {{ .Init.host }}`,
+		"dependencies": `
+- name: thing
+  version: 1.x.x
+  repository: oci://registry.example.com/charts
+  alias: {{ .Init.host }}`,
+		"secrets": `
+password:
+  name: {{ .Init.host }}
+  key: password`,
+	})
+	require.NoError(t, err)
+	out, err := p.Provision(context.Background(), &provisioners.Input{
+		ResourceUid:    "thing.default#w.r",
+		ResourceGuid:   "abc",
+		ResourceType:   "thing",
+		ResourceClass:  "default",
+		ResourceId:     "w.r",
+		ResourceParams: map[string]interface{}{"port": 5432},
+		ResourceState:  map[string]interface{}{"counter": 2},
+		SharedState:    map[string]interface{}{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"counter": 3}, out.ResourceState)
+	assert.Equal(t, map[string]interface{}{"seen": "abc"}, out.SharedState)
+	assert.Equal(t, map[string]interface{}{"host": "w-r", "port": 5432, "counter": 3, "seen": "abc"}, out.ResourceOutputs)
+	assert.Equal(t, map[string]interface{}{"extra": map[string]interface{}{"host": "w-r"}}, out.Values)
+	assert.Equal(t, []state.ChartDependency{
+		{Name: "thing", Version: "1.x.x", Repository: "oci://registry.example.com/charts", Alias: "w-r"},
+	}, out.Dependencies)
+	assert.Equal(t, map[string]state.SecretKeyRef{"password": {Name: "w-r", Key: "password"}}, out.Secrets)
+}
+
+func TestProvision_bad_output(t *testing.T) {
+	p, err := Parse(map[string]interface{}{
+		"uri":     "template://example",
+		"type":    "thing",
+		"outputs": `[not a map]`,
+	})
+	require.NoError(t, err)
+	_, err = p.Provision(context.Background(), &provisioners.Input{})
+	assert.ErrorContains(t, err, "outputs template failed: failed to decode output")
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// LockFileName is the name of the lock file that is held next to the state file while a command changes the state.
+	LockFileName = "state.lock"
+	// DefaultLockTimeout is how long a command waits for another one to release the lock.
+	DefaultLockTimeout = 30 * time.Second
+	// StaleLockAge is the age after which a lock is assumed to be left behind by a process that did not exit cleanly
+	// and is taken over.
+	StaleLockAge = 10 * time.Minute
+)
+
+// lockGuardSuffix is added to the lock file name for the guard file that is held while a lock file is removed.
+const lockGuardSuffix = ".guard"
+
+// lockPollInterval is how often a held lock is checked while waiting for it.
+var lockPollInterval = 100 * time.Millisecond
+
+// Lock is an advisory lock on the state of a state directory. It only guards against other score-helm commands that
+// take the same lock.
+type Lock struct {
+	path     string
+	timeout  time.Duration
+	staleAge time.Duration
+	// content is what this lock wrote to the lock file, which tells it apart from a lock that took over from it.
+	content string
+}
+
+// Lock takes the lock on the state, waiting up to the timeout for another process to release it. A lock that is older
+// than StaleLockAge is taken over. The state should be loaded again after the lock is taken, since another process may
+// have changed it in the meantime.
+func (sd *StateDirectory) Lock(timeout time.Duration) (*Lock, error) {
+	return acquireLock(filepath.Join(sd.StatePath(), LockFileName), timeout, StaleLockAge)
+}
+
+// acquireLock creates the lock file exclusively, so that only one process holds it. The lock file is only ever removed
+// while holding the guard file and after checking it again under the guard. Since the lock file can only be created
+// when it does not exist, a process cannot remove a lock that another process took after the first found it stale.
+func acquireLock(path string, timeout time.Duration, staleAge time.Duration) (*Lock, error) {
+	hostname, _ := os.Hostname()
+	content := fmt.Sprintf(
+		"pid %d on %s at %s\n%016x\n", os.Getpid(), hostname, time.Now().UTC().Format(time.RFC3339), rand.Uint64(),
+	)
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(content)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file '%s': %w", path, err)
+			}
+			return &Lock{path: path, timeout: timeout, staleAge: staleAge, content: content}, nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file '%s': %w", path, err)
+		}
+
+		if isStale(path, staleAge) {
+			if err := withLockGuard(path, deadline, staleAge, func() error {
+				// another process may have taken over the stale lock since, in which case its lock is not stale
+				if !isStale(path, staleAge) {
+					return nil
+				}
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to remove stale lock file '%s': %w", path, err)
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			holder := "another process"
+			if raw, err := os.ReadFile(path); err == nil {
+				if line, _, _ := strings.Cut(string(raw), "\n"); strings.TrimSpace(line) != "" {
+					holder = strings.TrimSpace(line)
+				}
+			}
+			return nil, fmt.Errorf(
+				"state is locked by %s, gave up after %s: if no other score-helm command is running, remove '%s'",
+				holder, timeout, path,
+			)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// isStale returns whether the lock file exists and is older than the stale age.
+func isStale(path string, staleAge time.Duration) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > staleAge
+}
+
+// withLockGuard runs fn while holding the guard file of the lock file, waiting until the deadline for another process
+// to release the guard. The guard is only held for as long as it takes to check and remove the lock file, so a guard
+// that is held past the deadline or is older than the stale age was most likely left behind by a process that did not
+// exit cleanly.
+func withLockGuard(path string, deadline time.Time, staleAge time.Duration, fn func() error) error {
+	guardPath := path + lockGuardSuffix
+	for {
+		f, err := os.OpenFile(guardPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = f.Close()
+			defer func() {
+				_ = os.Remove(guardPath)
+			}()
+			return fn()
+		} else if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create lock guard file '%s': %w", guardPath, err)
+		}
+		if isStale(guardPath, staleAge) || !time.Now().Before(deadline) {
+			return fmt.Errorf(
+				"lock guard file '%s' is still held: if no other score-helm command is running, remove '%s'",
+				guardPath, guardPath,
+			)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Release removes the lock file so that other processes can take the lock. A lock file that no longer holds the
+// content of this lock was taken over by another process after this one held it for longer than the stale age, and is
+// left in place. Release waits for the guard file for as long as the lock was allowed to wait when it was taken.
+func (l *Lock) Release() error {
+	return withLockGuard(l.path, time.Now().Add(l.timeout), l.staleAge, func() error {
+		raw, err := os.ReadFile(l.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read lock file '%s': %w", l.path, err)
+		} else if string(raw) != l.content {
+			return fmt.Errorf("lock file '%s' was taken over by another process while this one held it", l.path)
+		}
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove lock file '%s': %w", l.path, err)
+		}
+		return nil
+	})
+}
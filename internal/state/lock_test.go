@@ -0,0 +1,149 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setFastLockPolling(t *testing.T) {
+	previous := lockPollInterval
+	lockPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		lockPollInterval = previous
+	})
+}
+
+func TestAcquireLock_concurrent_takeover_of_stale_lock(t *testing.T) {
+	setFastLockPolling(t)
+	path := filepath.Join(t.TempDir(), LockFileName)
+	staleAge := time.Minute
+
+	for round := 0; round < 20; round++ {
+		require.NoError(t, os.WriteFile(path, []byte("pid 1 on elsewhere\n"), 0644))
+		old := time.Now().Add(-2 * staleAge)
+		require.NoError(t, os.Chtimes(path, old, old))
+
+		var holders, maxHolders atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lock, err := acquireLock(path, 10*time.Second, staleAge)
+				if !assert.NoError(t, err) {
+					return
+				}
+				n := holders.Add(1)
+				for {
+					if m := maxHolders.Load(); n <= m || maxHolders.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				assert.NoError(t, lock.Release())
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), maxHolders.Load(), "round %d", round)
+		assert.NoFileExists(t, path)
+		assert.NoFileExists(t, path+lockGuardSuffix)
+	}
+}
+
+func TestAcquireLock_timeout(t *testing.T) {
+	setFastLockPolling(t)
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock, err := acquireLock(path, time.Second, time.Minute)
+	require.NoError(t, err)
+
+	_, err = acquireLock(path, 10*time.Millisecond, time.Minute)
+	assert.ErrorContains(t, err, "state is locked by pid ")
+	assert.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+}
+
+func TestLockRelease_after_takeover(t *testing.T) {
+	setFastLockPolling(t)
+	path := filepath.Join(t.TempDir(), LockFileName)
+	staleAge := time.Minute
+	first, err := acquireLock(path, time.Second, staleAge)
+	require.NoError(t, err)
+
+	// the first lock is held for longer than the stale age and another process takes it over
+	old := time.Now().Add(-2 * staleAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+	second, err := acquireLock(path, time.Second, staleAge)
+	require.NoError(t, err)
+
+	assert.EqualError(t, first.Release(), "lock file '"+path+"' was taken over by another process while this one held it")
+	assert.FileExists(t, path)
+	assert.NoError(t, second.Release())
+	assert.NoFileExists(t, path)
+}
+
+func TestLockRelease_with_stale_guard(t *testing.T) {
+	setFastLockPolling(t)
+	path := filepath.Join(t.TempDir(), LockFileName)
+	staleAge := time.Minute
+	require.NoError(t, os.WriteFile(path, []byte("pid 1 on elsewhere\n"), 0644))
+	require.NoError(t, os.WriteFile(path+lockGuardSuffix, nil, 0644))
+	old := time.Now().Add(-2 * staleAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+	require.NoError(t, os.Chtimes(path+lockGuardSuffix, old, old))
+
+	_, err := acquireLock(path, time.Second, staleAge)
+	assert.EqualError(t, err, "lock guard file '"+path+lockGuardSuffix+"' is still held: if no other score-helm command is running, remove '"+path+lockGuardSuffix+"'")
+}
+
+func TestLock_with_fresh_leftover_guard(t *testing.T) {
+	setFastLockPolling(t)
+	path := filepath.Join(t.TempDir(), LockFileName)
+	staleAge := time.Minute
+	expected := "lock guard file '" + path + lockGuardSuffix + "' is still held: if no other score-helm command is running, remove '" + path + lockGuardSuffix + "'"
+
+	lock, err := acquireLock(path, 50*time.Millisecond, staleAge)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+lockGuardSuffix, nil, 0644))
+
+	// the guard is not stale, so the lock is not released until the wait for the guard runs out
+	start := time.Now()
+	assert.EqualError(t, lock.Release(), expected)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.FileExists(t, path)
+
+	// a stale lock cannot be taken over either until the guard is removed
+	old := time.Now().Add(-2 * staleAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+	start = time.Now()
+	_, err = acquireLock(path, 50*time.Millisecond, staleAge)
+	assert.EqualError(t, err, expected)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	require.NoError(t, os.Remove(path+lockGuardSuffix))
+	lock, err = acquireLock(path, 50*time.Millisecond, staleAge)
+	require.NoError(t, err)
+	assert.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the state file written by this version of score-helm. It must be increased, and a
+// migration added, whenever the state changes in a way that older versions of score-helm cannot read.
+const CurrentVersion = 1
+
+// StateExtras holds the score-helm specific fields at the top level of the state file.
+type StateExtras struct {
+	// Version is the version of the state file. State files written before the version was recorded are version 0.
+	Version int `yaml:"version"`
+}
+
+// migrations holds the migration of each state file version to the next one, so migrations[0] migrates version 0 to
+// version 1. Each migration changes the decoded state file in place.
+var migrations = []func(doc map[string]interface{}) error{
+	// Version 0 is the state before the version was recorded, which has the same layout as version 1.
+	func(doc map[string]interface{}) error {
+		return nil
+	},
+}
+
+// migrateState brings the content of a state file up to the current version, returning the migrated content. An error
+// is returned if the state file was written by a newer version of score-helm.
+func migrateState(content []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		if version, ok = raw.(int); !ok || version < 0 {
+			return nil, fmt.Errorf("version: expected a non-negative integer, got '%v'", raw)
+		}
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf(
+			"state file has version %d but this version of score-helm only supports up to version %d, please upgrade score-helm",
+			version, CurrentVersion,
+		)
+	} else if version == CurrentVersion {
+		return content, nil
+	}
+
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate state from version %d to %d: %w", version, version+1, err)
+		}
+	}
+	doc["version"] = CurrentVersion
+
+	out := new(bytes.Buffer)
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode migrated state: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The Score Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// v0StateFile is a state file written before the version was recorded.
+const v0StateFile = `workloads:
+  example:
+    spec:
+      apiVersion: score.dev/v1b1
+      metadata:
+        name: example
+      containers:
+        main:
+          image: busybox
+      resources:
+        db:
+          type: postgres
+    file: score.yaml
+resources:
+  postgres.default#example.db:
+    guid: 6b3c0c06-8f44-4e2c-9a1e-1d3f6d0c8a51
+    type: postgres
+    class: default
+    id: example.db
+    metadata: {}
+    params: {}
+    source_workload: example
+    provisioner: template://default-provisioners/postgres
+    state:
+      name: pg-example
+    outputs:
+      host: pg-example
+    values:
+      things: true
+shared_state: {}
+`
+
+func TestMigrateState(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		// expected is the content that is returned unchanged, or empty when the content is migrated
+		expected string
+		err      string
+	}{
+		{name: "v0 with missing version", content: v0StateFile},
+		{name: "v0 with version", content: v0StateFile + "version: 0\n"},
+		{name: "empty", content: ""},
+		{name: "current", content: "workloads: {}\nversion: 1\n", expected: "workloads: {}\nversion: 1\n"},
+		{name: "negative", content: "workloads: {}\nversion: -1\n", err: "version: expected a non-negative integer, got '-1'"},
+		{name: "not a number", content: "workloads: {}\nversion: one\n", err: "version: expected a non-negative integer, got 'one'"},
+		{
+			name:    "future",
+			content: "workloads: {}\nsome_future_field: true\nversion: 2\n",
+			err:     "state file has version 2 but this version of score-helm only supports up to version 1, please upgrade score-helm",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := migrateState([]byte(tc.content))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, string(out))
+			}
+
+			// the migrated state decodes with the same strict decoder that loading the state uses
+			var migrated State
+			dec := yaml.NewDecoder(bytes.NewReader(out))
+			dec.KnownFields(true)
+			require.NoError(t, dec.Decode(&migrated))
+			assert.Equal(t, CurrentVersion, migrated.Extras.Version)
+		})
+	}
+}
+
+func TestMigrateState_keeps_v0_content(t *testing.T) {
+	out, err := migrateState([]byte(v0StateFile))
+	require.NoError(t, err)
+	var migrated State
+	dec := yaml.NewDecoder(bytes.NewReader(out))
+	dec.KnownFields(true)
+	require.NoError(t, dec.Decode(&migrated))
+
+	assert.Equal(t, "score.yaml", *migrated.Workloads["example"].File)
+	res := migrated.Resources["postgres.default#example.db"]
+	assert.Equal(t, "template://default-provisioners/postgres", res.ProvisionerUri)
+	assert.Equal(t, map[string]interface{}{"name": "pg-example"}, res.State)
+	assert.Equal(t, map[string]interface{}{"host": "pg-example"}, res.Outputs)
+	assert.Equal(t, map[string]interface{}{"things": true}, res.Extras.Values)
+}
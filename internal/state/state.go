@@ -18,41 +18,111 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/score-spec/score-go/framework"
 	"gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-helm/internal/extensions"
 )
 
 const (
 	DefaultRelativeStateDirectory = ".score-helm"
 	FileName                      = "state.yaml"
+	// ValuesTemplateFileName is the file in the state directory that replaces the default values template when present.
+	ValuesTemplateFileName = "values.tmpl"
+	// EnvironmentsDirectory is the directory in the state directory that holds the state of each environment.
+	EnvironmentsDirectory = "envs"
 )
 
-type WorkloadExtras struct{}
+type WorkloadExtras struct {
+	// Extensions holds the Kubernetes-specific settings of the workload from the last extensions file that named it.
+	Extensions *extensions.Workload `yaml:"extensions,omitempty"`
+}
+
+type ResourceExtras struct {
+	// Values is the values fragment returned by the provisioner. It is merged into the values output of the
+	// workloads that depend on this resource.
+	Values map[string]interface{} `yaml:"values,omitempty"`
+	// Dependencies are the charts returned by the provisioner that are added to the dependencies of a generated chart,
+	// such as a subchart that deploys the resource.
+	Dependencies []ChartDependency `yaml:"dependencies,omitempty"`
+	// Secrets holds the Kubernetes Secret key of each output that is a secret, keyed by output name. Variables that
+	// reference these outputs are read from the Secret rather than written to the values.
+	Secrets map[string]SecretKeyRef `yaml:"secrets,omitempty"`
+	// Consumers are the names of the workloads that use this resource, in sorted order. A resource with an explicit
+	// id may be shared by several workloads, which all read the same outputs.
+	Consumers []string `yaml:"consumers,omitempty"`
+}
+
+// SecretKeyRef refers to a key of a Kubernetes Secret.
+type SecretKeyRef struct {
+	Name string `yaml:"name" json:"name"`
+	Key  string `yaml:"key" json:"key"`
+}
+
+// ChartDependency is an entry of the dependencies of a Chart.yaml.
+type ChartDependency struct {
+	Name       string `yaml:"name" json:"name"`
+	Version    string `yaml:"version,omitempty" json:"version,omitempty"`
+	Repository string `yaml:"repository,omitempty" json:"repository,omitempty"`
+	Alias      string `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Condition  string `yaml:"condition,omitempty" json:"condition,omitempty"`
+}
 
-type ResourceExtras struct{}
+// Key returns the name that the values of the dependency are held under, which is the alias if set.
+func (d ChartDependency) Key() string {
+	if d.Alias != "" {
+		return d.Alias
+	}
+	return d.Name
+}
 
-type State = framework.State[framework.NoExtras, WorkloadExtras, ResourceExtras]
+type State = framework.State[StateExtras, WorkloadExtras, ResourceExtras]
 
 // The StateDirectory holds the local state of the project, including any configuration, extensions,
 // plugins, or resource provisioning state when possible.
 type StateDirectory struct {
 	// The path to the state directory
 	Path string
+	// Env is the name of the environment whose state is held in envs/<env> under the state directory, or empty for
+	// the default state.
+	Env string
 	// The current state file
 	State State
 }
 
+// StatePath returns the directory that holds the state file.
+func (sd *StateDirectory) StatePath() string {
+	if sd.Env == "" {
+		return sd.Path
+	}
+	return filepath.Join(sd.Path, EnvironmentsDirectory, sd.Env)
+}
+
+// ConfigPaths returns the directories that provisioners and the values template are loaded from, in order of
+// precedence. The directory of an environment takes precedence over the shared state directory.
+func (sd *StateDirectory) ConfigPaths() []string {
+	if sd.Env == "" {
+		return []string{sd.Path}
+	}
+	return []string{sd.StatePath(), sd.Path}
+}
+
 // Persist ensures that the directory is created and that the current config file has been written with the latest settings.
 func (sd *StateDirectory) Persist() error {
 	if sd.Path == "" {
 		return fmt.Errorf("path not set")
 	}
-	if err := os.Mkdir(sd.Path, 0755); err != nil && !errors.Is(err, os.ErrExist) {
-		return fmt.Errorf("failed to create directory '%s': %w", sd.Path, err)
+	if err := os.MkdirAll(sd.StatePath(), 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", sd.StatePath(), err)
 	}
+	sd.State.Extras.Version = CurrentVersion
 	out := new(bytes.Buffer)
 	enc := yaml.NewEncoder(out)
 	enc.SetIndent(2)
@@ -61,9 +131,9 @@ func (sd *StateDirectory) Persist() error {
 	}
 
 	// important that we overwrite this file atomically via an inode move
-	if err := os.WriteFile(filepath.Join(sd.Path, FileName+".temp"), out.Bytes(), 0755); err != nil {
+	if err := os.WriteFile(filepath.Join(sd.StatePath(), FileName+".temp"), out.Bytes(), 0755); err != nil {
 		return fmt.Errorf("failed to write state: %w", err)
-	} else if err := os.Rename(filepath.Join(sd.Path, FileName+".temp"), filepath.Join(sd.Path, FileName)); err != nil {
+	} else if err := os.Rename(filepath.Join(sd.StatePath(), FileName+".temp"), filepath.Join(sd.StatePath(), FileName)); err != nil {
 		return fmt.Errorf("failed to complete writing state: %w", err)
 	}
 	return nil
@@ -71,8 +141,25 @@ func (sd *StateDirectory) Persist() error {
 
 // LoadStateDirectory loads the state directory for the given directory (usually PWD).
 func LoadStateDirectory(directory string) (*StateDirectory, bool, error) {
-	d := filepath.Join(directory, DefaultRelativeStateDirectory)
-	content, err := os.ReadFile(filepath.Join(d, FileName))
+	return LoadStateDirectoryAt(filepath.Join(directory, DefaultRelativeStateDirectory), "")
+}
+
+// ValidateEnv checks that an environment name can be used as a directory name.
+func ValidateEnv(env string) error {
+	if env == "." || env == ".." || strings.ContainsAny(env, `/\`) {
+		return fmt.Errorf("environment name '%s' is invalid, it must not be a path", env)
+	}
+	return nil
+}
+
+// LoadStateDirectoryAt loads the state of the given environment from the state directory at the given path. An empty
+// environment loads the default state.
+func LoadStateDirectoryAt(path string, env string) (*StateDirectory, bool, error) {
+	if err := ValidateEnv(env); err != nil {
+		return nil, false, err
+	}
+	sd := &StateDirectory{Path: path, Env: env}
+	content, err := os.ReadFile(filepath.Join(sd.StatePath(), FileName))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, false, nil
@@ -80,11 +167,111 @@ func LoadStateDirectory(directory string) (*StateDirectory, bool, error) {
 		return nil, true, fmt.Errorf("state file couldn't be read: %w", err)
 	}
 
+	// older state files are migrated before the strict decode so that fields added since then are accepted
+	if content, err = migrateState(content); err != nil {
+		return nil, true, fmt.Errorf("state file couldn't be decoded: %w", err)
+	}
+
 	var out State
 	dec := yaml.NewDecoder(bytes.NewReader(content))
 	dec.KnownFields(true)
 	if err := dec.Decode(&out); err != nil {
 		return nil, true, fmt.Errorf("state file couldn't be decoded: %w", err)
 	}
-	return &StateDirectory{d, out}, true, nil
+	sd.State = out
+	return sd, true, nil
+}
+
+// WithoutWorkload returns a copy of the state without the given workload and without the resources that no remaining
+// workload uses. The workload is removed from the consumers of the remaining resources. The uids of the removed
+// resources are returned in sorted order.
+func WithoutWorkload(s *State, workloadName string) (*State, []framework.ResourceUid, error) {
+	if _, ok := s.Workloads[workloadName]; !ok {
+		return nil, nil, fmt.Errorf("workload '%s' does not exist", workloadName)
+	}
+	out := *s
+	out.Workloads = maps.Clone(s.Workloads)
+	delete(out.Workloads, workloadName)
+
+	consumers := make(map[framework.ResourceUid][]string)
+	for _, name := range slices.Sorted(maps.Keys(out.Workloads)) {
+		for resName, res := range out.Workloads[name].Spec.Resources {
+			resUid := framework.NewResourceUid(name, resName, res.Type, res.Class, res.Id)
+			if !slices.Contains(consumers[resUid], name) {
+				consumers[resUid] = append(consumers[resUid], name)
+			}
+		}
+	}
+	out.Resources = maps.Clone(s.Resources)
+	removed := make([]framework.ResourceUid, 0)
+	for resUid, res := range s.Resources {
+		if len(consumers[resUid]) == 0 {
+			delete(out.Resources, resUid)
+			removed = append(removed, resUid)
+			continue
+		}
+		res.Extras.Consumers = consumers[resUid]
+		if res.SourceWorkload == workloadName {
+			res.SourceWorkload = consumers[resUid][0]
+		}
+		out.Resources[resUid] = res
+	}
+	slices.Sort(removed)
+	return &out, removed, nil
+}
+
+// WithPrimedResources returns a copy of the state with a resource for each resource of the workloads, like
+// framework.State.WithPrimedResources, and with the consumers of each resource recorded. A resource that is shared by
+// several workloads through its id must be declared with the same params and metadata by each of them, or an error
+// naming the conflicting workloads is returned.
+func WithPrimedResources(s *State) (*State, error) {
+	consumers := make(map[framework.ResourceUid][]string)
+	params := make(map[framework.ResourceUid]map[string]interface{})
+	metadata := make(map[framework.ResourceUid]map[string]interface{})
+	conflicts := make(map[framework.ResourceUid]string)
+	for _, workloadName := range slices.Sorted(maps.Keys(s.Workloads)) {
+		workload := s.Workloads[workloadName]
+		for _, resName := range slices.Sorted(maps.Keys(workload.Spec.Resources)) {
+			res := workload.Spec.Resources[resName]
+			resUid := framework.NewResourceUid(workloadName, resName, res.Type, res.Class, res.Id)
+			if !slices.Contains(consumers[resUid], workloadName) {
+				consumers[resUid] = append(consumers[resUid], workloadName)
+			}
+			// workloads that leave the params or metadata out take those of the others
+			if res.Params != nil {
+				if existing, ok := params[resUid]; !ok {
+					params[resUid] = res.Params
+				} else if !reflect.DeepEqual(existing, map[string]interface{}(res.Params)) && conflicts[resUid] == "" {
+					conflicts[resUid] = "params"
+				}
+			}
+			if res.Metadata != nil {
+				if existing, ok := metadata[resUid]; !ok {
+					metadata[resUid] = res.Metadata
+				} else if !reflect.DeepEqual(existing, map[string]interface{}(res.Metadata)) && conflicts[resUid] == "" {
+					conflicts[resUid] = "metadata"
+				}
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		errs := make([]error, 0, len(conflicts))
+		for _, resUid := range slices.Sorted(maps.Keys(conflicts)) {
+			errs = append(errs, fmt.Errorf(
+				"resource '%s': workloads %s share this resource but declare different %s",
+				resUid, strings.Join(consumers[resUid], ", "), conflicts[resUid],
+			))
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	out, err := s.WithPrimedResources()
+	if err != nil {
+		return nil, err
+	}
+	for resUid, res := range out.Resources {
+		res.Extras.Consumers = consumers[resUid]
+		out.Resources[resUid] = res
+	}
+	return out, nil
 }